@@ -4,10 +4,16 @@
 package config
 
 import (
+	"os"
+	"strings"
+
 	"github.com/kelseyhightower/envconfig"
 	"github.com/rs/zerolog/log"
 )
 
+// DefaultAccount is the name of the account of PostmanApiKey and PostmanBaseUrl.
+const DefaultAccount = "default"
+
 var (
 	Config Specification
 )
@@ -17,4 +23,26 @@ func ParseConfiguration() {
 	if err != nil {
 		log.Fatal().Err(err).Msgf("Failed to parse configuration from environment.")
 	}
+	if Config.PostmanApiKeyFile != "" {
+		content, err := os.ReadFile(Config.PostmanApiKeyFile)
+		if err != nil {
+			log.Fatal().Err(err).Msgf("Failed to read the Postman API key from %s.", Config.PostmanApiKeyFile)
+		}
+		// the file takes precedence over STEADYBIT_EXTENSION_POSTMAN_API_KEY
+		Config.PostmanApiKey = strings.TrimSpace(string(content))
+		if Config.PostmanApiKey == "" {
+			log.Fatal().Msgf("Failed to parse configuration from environment: the Postman API key file %s is empty.", Config.PostmanApiKeyFile)
+		}
+	}
+	if _, ok := Config.PostmanAccountApiKeys[DefaultAccount]; ok && Config.PostmanApiKey != "" {
+		log.Fatal().Msgf("Failed to parse configuration from environment: the account name %q is reserved for STEADYBIT_EXTENSION_POSTMAN_API_KEY.", DefaultAccount)
+	}
+	for name := range Config.PostmanAccountBaseUrls {
+		if _, ok := Config.PostmanAccountApiKeys[name]; !ok {
+			log.Fatal().Msgf("Failed to parse configuration from environment: STEADYBIT_EXTENSION_POSTMAN_ACCOUNT_BASE_URLS configures account %q, which has no API key.", name)
+		}
+	}
+	if Config.PostmanApiKey == "" && len(Config.PostmanAccountApiKeys) == 0 && Config.CollectionsDir == "" && Config.GitRepositoryUrl == "" && len(Config.CollectionUrls) == 0 && len(Config.OpenApiSpecifications) == 0 {
+		log.Fatal().Msgf("Failed to parse configuration from environment: STEADYBIT_EXTENSION_POSTMAN_API_KEY is required unless STEADYBIT_EXTENSION_POSTMAN_ACCOUNT_API_KEYS, STEADYBIT_EXTENSION_COLLECTIONS_DIR, STEADYBIT_EXTENSION_GIT_REPOSITORY_URL, STEADYBIT_EXTENSION_COLLECTION_URLS or STEADYBIT_EXTENSION_OPEN_API_SPECIFICATIONS is set.")
+	}
 }
@@ -3,8 +3,61 @@
 
 package config
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Specification struct {
-	PostmanBaseUrl                     string `json:"postmanBaseUrl" split_words:"true" required:"false" default:"https://api.getpostman.com"`
-	PostmanApiKey                      string `json:"postmanApiKey" split_words:"true" required:"true"`
-	PostmanCollectionDiscoveryInterval string `json:"postmanCollectionDiscoveryInterval" split_words:"true" required:"false" default:"3h"`
+	PostmanBaseUrl                     string   `json:"postmanBaseUrl" split_words:"true" required:"false" default:"https://api.getpostman.com"`
+	PostmanApiKey                      string   `json:"postmanApiKey" split_words:"true" required:"false"`
+	PostmanApiKeyFile                  string   `json:"postmanApiKeyFile" split_words:"true" required:"false"`
+	PostmanApiKeyFileCheckInterval     string   `json:"postmanApiKeyFileCheckInterval" split_words:"true" required:"false" default:"30s"`
+	PostmanCollectionDiscoveryInterval string   `json:"postmanCollectionDiscoveryInterval" split_words:"true" required:"false" default:"3h"`
+	PostmanWorkspaces                  []string `json:"postmanWorkspaces" split_words:"true" required:"false"`
+	PostmanHealthCheckInterval         string   `json:"postmanHealthCheckInterval" split_words:"true" required:"false" default:"1m"`
+	RunHistoryPath                     string   `json:"runHistoryPath" split_words:"true" required:"false" default:"/tmp/steadybit-postman-runs.db"`
+	RunHistorySize                     int      `json:"runHistorySize" split_words:"true" required:"false" default:"100"`
+	MaxArtifactSize                    int64    `json:"maxArtifactSize" split_words:"true" required:"false" default:"10485760"`
+	RedactHeaders                      []string `json:"redactHeaders" split_words:"true" required:"false" default:"Authorization,Cookie,Set-Cookie"`
+	RedactBodyFields                   []string `json:"redactBodyFields" split_words:"true" required:"false"`
+	SecretMaskPatterns                 []string `json:"secretMaskPatterns" split_words:"true" required:"false"`
+	CacheDir                           string   `json:"cacheDir" split_words:"true" required:"false" default:"/tmp/steadybit-postman-cache"`
+	CacheMaxStaleness                  string   `json:"cacheMaxStaleness" split_words:"true" required:"false" default:"24h"`
+	CollectionsDir                     string   `json:"collectionsDir" split_words:"true" required:"false"`
+	GitRepositoryUrl                   string   `json:"gitRepositoryUrl" split_words:"true" required:"false"`
+	GitBranch                          string   `json:"gitBranch" split_words:"true" required:"false"`
+	GitCollectionPaths                 []string `json:"gitCollectionPaths" split_words:"true" required:"false"`
+	GitCloneDir                        string   `json:"gitCloneDir" split_words:"true" required:"false" default:"/tmp/steadybit-postman-git"`
+	CollectionUrls                     []string `json:"collectionUrls" split_words:"true" required:"false"`
+	EnvironmentUrls                    []string `json:"environmentUrls" split_words:"true" required:"false"`
+	HttpSourceBearerToken              string   `json:"httpSourceBearerToken" split_words:"true" required:"false"`
+	HttpSourceUsername                 string   `json:"httpSourceUsername" split_words:"true" required:"false"`
+	HttpSourcePassword                 string   `json:"httpSourcePassword" split_words:"true" required:"false"`
+	OpenApiSpecifications              []string `json:"openApiSpecifications" split_words:"true" required:"false"`
+
+	// PostmanAccountApiKeys and PostmanAccountBaseUrls configure named accounts besides the one of PostmanApiKey,
+	// e.g. of a team in the EU data region.
+	PostmanAccountApiKeys  AccountSettings `json:"postmanAccountApiKeys" split_words:"true" required:"false"`
+	PostmanAccountBaseUrls AccountSettings `json:"postmanAccountBaseUrls" split_words:"true" required:"false"`
+}
+
+// AccountSettings maps account names to a setting of the account, e.g. team-eu:https://api.eu.postman.com.
+// Unlike maps parsed by envconfig, the values may contain colons.
+type AccountSettings map[string]string
+
+func (s *AccountSettings) Decode(value string) error {
+	settings := AccountSettings{}
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, setting, ok := strings.Cut(pair, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid account setting %q, expected <account>:<value>", pair)
+		}
+		settings[strings.TrimSpace(name)] = strings.TrimSpace(setting)
+	}
+	*s = settings
+	return nil
 }
@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/steadybit/extension-postman/v2/config"
+)
+
+const attributeAccount = "postman.account"
+
+// postmanAccount is a Postman team the extension accesses with its own API key, e.g. a team in
+// the EU data region with its own base URL.
+type postmanAccount struct {
+	name    string
+	apiKey  string
+	baseUrl string
+}
+
+// getPostmanAccounts returns the account of PostmanApiKey, named "default", followed by the
+// named accounts of PostmanAccountApiKeys sorted by name.
+func getPostmanAccounts() []postmanAccount {
+	var accounts []postmanAccount
+	if apiKey := getDefaultApiKey(); apiKey != "" {
+		accounts = append(accounts, postmanAccount{
+			name:    config.DefaultAccount,
+			apiKey:  apiKey,
+			baseUrl: strings.TrimSuffix(config.Config.PostmanBaseUrl, "/"),
+		})
+	}
+	names := make([]string, 0, len(config.Config.PostmanAccountApiKeys))
+	for name := range config.Config.PostmanAccountApiKeys {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		baseUrl := config.Config.PostmanAccountBaseUrls[name]
+		if baseUrl == "" {
+			baseUrl = config.Config.PostmanBaseUrl
+		}
+		accounts = append(accounts, postmanAccount{
+			name:    name,
+			apiKey:  config.Config.PostmanAccountApiKeys[name],
+			baseUrl: strings.TrimSuffix(baseUrl, "/"),
+		})
+	}
+	return accounts
+}
+
+// getPostmanAccount returns the account a target was discovered with. Targets without account
+// attribute were discovered before accounts could be configured and belong to the default
+// account, or to the only account if there is just one.
+func getPostmanAccount(attributes map[string][]string) (postmanAccount, error) {
+	accounts := getPostmanAccounts()
+	name := config.DefaultAccount
+	if values := attributes[attributeAccount]; len(values) > 0 {
+		name = values[0]
+	} else if len(accounts) == 1 {
+		return accounts[0], nil
+	}
+	for _, account := range accounts {
+		if account.name == name {
+			return account, nil
+		}
+	}
+	return postmanAccount{}, fmt.Errorf("postman account %q is not configured", name)
+}
+
+// accountTargetId keeps the ids of the targets of the default account unchanged, while the ids
+// of other accounts are prefixed, as their keys may access the same collections or monitors.
+func accountTargetId(account postmanAccount, id string) string {
+	if account.name == config.DefaultAccount {
+		return id
+	}
+	return account.name + ":" + id
+}
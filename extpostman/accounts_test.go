@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steadybit/extension-postman/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPostmanAccountApiStub serves one collection to requests authenticated with the API key.
+func newPostmanAccountApiStub(t *testing.T, apiKey, collectionId, collectionName string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != apiKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/collections":
+			_, _ = w.Write([]byte(`{"collections":[{"id":"` + collectionId + `","name":"` + collectionName + `"}]}`))
+		case "/collections/" + collectionId:
+			_, _ = w.Write([]byte(`{"collection":{"info":{"name":"` + collectionName + `"},"item":[]}}`))
+		case "/environments":
+			_, _ = w.Write([]byte(`{"environments":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDiscoverAndRunCollectionsOfAllAccounts(t *testing.T) {
+	defaultServer := newPostmanAccountApiStub(t, "default-key", "c1", "checkout")
+	euServer := newPostmanAccountApiStub(t, "eu-key", "c1", "payment")
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_API_KEY", "default-key")
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_BASE_URL", defaultServer.URL)
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_ACCOUNT_API_KEYS", "team-eu:eu-key")
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_ACCOUNT_BASE_URLS", "team-eu:"+euServer.URL)
+	config.ParseConfiguration()
+	t.Cleanup(func() {
+		config.Config.PostmanAccountApiKeys = nil
+		config.Config.PostmanAccountBaseUrls = nil
+	})
+
+	targets, err := discoverAllCollections()
+
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+	assert.Equal(t, "c1", targets[0].Id)
+	assert.Equal(t, []string{"default"}, targets[0].Attributes["postman.account"])
+	assert.Equal(t, []string{"checkout"}, targets[0].Attributes["postman.collection.name"])
+	// both accounts access a collection with the same id
+	assert.Equal(t, "team-eu:c1", targets[1].Id)
+	assert.Equal(t, []string{"team-eu"}, targets[1].Attributes["postman.account"])
+	assert.Equal(t, []string{"payment"}, targets[1].Attributes["postman.collection.name"])
+
+	// runs download the collection with the account of the target
+	source, err := getCollectionSource(targets[1].Attributes)
+	require.NoError(t, err)
+	destPath := filepath.Join(t.TempDir(), "collection.json")
+	_, err = source.fetchCollection(context.Background(), targets[1].Attributes, destPath)
+	require.NoError(t, err)
+	content, err := os.ReadFile(destPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "payment")
+
+	// targets discovered before accounts were introduced belong to the default account
+	account, err := getPostmanAccount(map[string][]string{})
+	require.NoError(t, err)
+	assert.Equal(t, "default", account.name)
+	_, err = getPostmanAccount(map[string][]string{"postman.account": {"unknown"}})
+	assert.ErrorContains(t, err, `postman account "unknown" is not configured`)
+}
+
+func TestGetPostmanAccountsTrimTrailingSlashesOfBaseUrls(t *testing.T) {
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_API_KEY", "default-key")
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_BASE_URL", "https://api.getpostman.com/")
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_ACCOUNT_API_KEYS", "team-eu:eu-key")
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_ACCOUNT_BASE_URLS", "team-eu:https://api.eu.postman.com/")
+	config.ParseConfiguration()
+	t.Cleanup(func() {
+		config.Config.PostmanAccountApiKeys = nil
+		config.Config.PostmanAccountBaseUrls = nil
+	})
+
+	accounts := getPostmanAccounts()
+
+	require.Len(t, accounts, 2)
+	assert.Equal(t, "https://api.getpostman.com", accounts[0].baseUrl)
+	assert.Equal(t, "https://api.eu.postman.com", accounts[1].baseUrl)
+}
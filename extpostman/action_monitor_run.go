@@ -0,0 +1,293 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/action-kit/go/action_kit_sdk"
+	"github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extconversion"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+const (
+	monitorRunStatusSuccess = "success"
+	monitorRunStatusFailed  = "failed"
+)
+
+// monitorRuns holds the monitor runs in progress by run id. The Postman API answers the run
+// request only once the monitor finished, so the request is sent in the background and Status
+// polls for its result.
+var monitorRuns sync.Map
+
+type monitorRun struct {
+	done   chan struct{}
+	cancel context.CancelFunc
+	result *PostmanMonitorRun
+	err    error
+}
+
+type PostmanMonitorAction struct {
+}
+
+type PostmanMonitorState struct {
+	MonitorId   string `json:"monitorId"`
+	MonitorName string `json:"monitorName"`
+	// Account is the name of the Postman account the monitor was discovered with.
+	Account string `json:"account"`
+	// RunId identifies the run in progress in monitorRuns.
+	RunId     string     `json:"runId"`
+	Timeout   int        `json:"timeout"`
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+}
+
+type PostmanMonitorConfig struct {
+	Timeout int
+}
+
+func NewPostmanMonitorAction() action_kit_sdk.Action[PostmanMonitorState] {
+	return PostmanMonitorAction{}
+}
+
+// Make sure PostmanMonitorAction implements all required interfaces
+var _ action_kit_sdk.Action[PostmanMonitorState] = (*PostmanMonitorAction)(nil)
+var _ action_kit_sdk.ActionWithStatus[PostmanMonitorState] = (*PostmanMonitorAction)(nil)
+var _ action_kit_sdk.ActionWithStop[PostmanMonitorState] = (*PostmanMonitorAction)(nil)
+
+func (f PostmanMonitorAction) NewEmptyState() PostmanMonitorState {
+	return PostmanMonitorState{}
+}
+
+func (f PostmanMonitorAction) Describe() action_kit_api.ActionDescription {
+	return action_kit_api.ActionDescription{
+		Id:          monitorTargetID + ".run",
+		Label:       "Postman Monitor",
+		Description: "Run a Postman Monitor on the Postman infrastructure and check its result.",
+		Version:     extbuild.GetSemverVersionStringOrUnknown(),
+		Kind:        action_kit_api.Check,
+		Icon:        new(icon),
+		Technology:  new("Postman"),
+
+		TargetSelection: new(action_kit_api.TargetSelection{
+			TargetType: monitorTargetID,
+			SelectionTemplates: new([]action_kit_api.TargetSelectionTemplate{
+				{
+					Label: "monitor name",
+					Query: "postman.monitor.name=\"\"",
+				},
+				{
+					Label: "monitor id",
+					Query: "postman.monitor.id=\"\"",
+				},
+			}),
+		}),
+		TimeControl: action_kit_api.TimeControlInternal,
+		Parameters: []action_kit_api.ActionParameter{
+			{
+				Name:         "duration",
+				Label:        "Estimated duration",
+				DefaultValue: new("30s"),
+				Description:  new("The step runs until the monitor run finished. You can set this estimation to size the step in the experiment editor for a better understanding of the time schedule."),
+				Required:     new(true),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+			},
+			{
+				Name:         "timeout",
+				Label:        "Timeout",
+				Description:  new("The time to wait for the monitor run to finish."),
+				Required:     new(false),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("10m"),
+				Advanced:     new(true),
+			},
+		},
+		Prepare: action_kit_api.MutatingEndpointReference{},
+		Start:   action_kit_api.MutatingEndpointReference{},
+		Status:  new(action_kit_api.MutatingEndpointReferenceWithCallInterval{}),
+		Stop:    new(action_kit_api.MutatingEndpointReference{}),
+	}
+}
+
+func (f PostmanMonitorAction) Prepare(_ context.Context, state *PostmanMonitorState, raw action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+	var request PostmanMonitorConfig
+	if err := extconversion.Convert(raw.Config, &request); err != nil {
+		return nil, extension_kit.ToError("Failed to unmarshal the config.", err)
+	}
+
+	monitorId, err := singleAttribute(raw.Target.Attributes, attributeMonitorId)
+	if err != nil {
+		return nil, extension_kit.ToError("Failed to determine the monitor.", err)
+	}
+	account, err := getPostmanAccount(raw.Target.Attributes)
+	if err != nil {
+		return nil, extension_kit.ToError("Failed to determine the Postman account of the monitor.", err)
+	}
+	state.MonitorId = monitorId
+	state.Account = account.name
+	state.MonitorName = monitorId
+	if names := raw.Target.Attributes[attributeMonitorName]; len(names) > 0 {
+		state.MonitorName = names[0]
+	}
+	state.Timeout = request.Timeout
+	if state.Timeout <= 0 {
+		state.Timeout = int((10 * time.Minute).Milliseconds())
+	}
+	state.RunId = raw.ExecutionId.String()
+	if raw.ExecutionId == uuid.Nil {
+		state.RunId = uuid.NewString()
+	}
+	return nil, nil
+}
+
+func (f PostmanMonitorAction) Start(_ context.Context, state *PostmanMonitorState) (*action_kit_api.StartResult, error) {
+	account, err := getPostmanAccount(map[string][]string{attributeAccount: {state.Account}})
+	if err != nil {
+		return nil, new(extension_kit.ToError("Failed to determine the Postman account of the monitor.", err))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(state.Timeout)*time.Millisecond)
+	run := &monitorRun{done: make(chan struct{}), cancel: cancel}
+	if _, loaded := monitorRuns.LoadOrStore(state.RunId, run); loaded {
+		cancel()
+		return nil, new(extension_kit.ToError(fmt.Sprintf("Monitor run %s was already started.", state.RunId), nil))
+	}
+
+	log.Info().Msgf("Starting run of monitor %s", state.MonitorId)
+	go func() {
+		defer close(run.done)
+		defer cancel()
+		run.result, run.err = runPostmanMonitor(ctx, account, state.MonitorId)
+	}()
+	state.StartedAt = new(time.Now())
+	return &action_kit_api.StartResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Started run of monitor %s on the Postman infrastructure", state.MonitorName),
+		}}),
+	}, nil
+}
+
+func (f PostmanMonitorAction) Status(_ context.Context, state *PostmanMonitorState) (*action_kit_api.StatusResult, error) {
+	value, ok := monitorRuns.Load(state.RunId)
+	if !ok {
+		return nil, new(extension_kit.ToError(fmt.Sprintf("Monitor run %s is unknown, the extension may have been restarted.", state.RunId), nil))
+	}
+	run := value.(*monitorRun)
+	select {
+	case <-run.done:
+	default:
+		return &action_kit_api.StatusResult{Completed: false}, nil
+	}
+	monitorRuns.Delete(state.RunId)
+
+	if run.err != nil {
+		log.Error().Msgf("Failed to run monitor %s: %s", state.MonitorId, run.err)
+		return &action_kit_api.StatusResult{
+			Completed: true,
+			Error: &action_kit_api.ActionKitError{
+				Status: extutil.Ptr(action_kit_api.Errored),
+				Title:  fmt.Sprintf("Failed to run monitor %s", state.MonitorName),
+				Detail: new(run.err.Error()),
+			},
+		}, nil
+	}
+
+	verdict, messages := getMonitorRunVerdict(run.result)
+	log.Info().Msgf("Run of monitor %s finished with status %s", state.MonitorId, run.result.Info.Status)
+	return &action_kit_api.StatusResult{
+		Completed: true,
+		Error:     verdict,
+		Messages:  new(messages),
+	}, nil
+}
+
+// Stop cancels a monitor run that did not finish yet. The run on the Postman infrastructure
+// still completes, only its result is not waited for anymore.
+func (f PostmanMonitorAction) Stop(_ context.Context, state *PostmanMonitorState) (*action_kit_api.StopResult, error) {
+	if value, ok := monitorRuns.LoadAndDelete(state.RunId); ok {
+		log.Info().Msgf("Canceling run of monitor %s", state.MonitorId)
+		value.(*monitorRun).cancel()
+	}
+	return nil, nil
+}
+
+// getMonitorRunVerdict maps the stats and failures of the monitor run to the verdict of the
+// step and messages for each request and failure.
+func getMonitorRunVerdict(run *PostmanMonitorRun) (*action_kit_api.ActionKitError, []action_kit_api.Message) {
+	requestNames := make(map[int]string, len(run.Executions))
+	messages := make([]action_kit_api.Message, 0, len(run.Executions)+len(run.Failures)+1)
+	for _, execution := range run.Executions {
+		requestNames[execution.Id] = execution.Item.Name
+		messages = append(messages, action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("%s %s [%d, %dms]", execution.Request.Method, execution.Request.Url, execution.Response.Code, execution.Response.ResponseTime),
+			Fields: new(action_kit_api.MessageFields{
+				"request":      execution.Item.Name,
+				"statusCode":   fmt.Sprintf("%d", execution.Response.Code),
+				"responseTime": fmt.Sprintf("%d", execution.Response.ResponseTime),
+			}),
+		})
+	}
+	for _, failure := range run.Failures {
+		name, message := failure.Name, failure.Message
+		if failure.Error != nil {
+			if failure.Error.Name != "" {
+				name = failure.Error.Name
+			}
+			if failure.Error.Message != "" {
+				message = failure.Error.Message
+			}
+		}
+		fields := action_kit_api.MessageFields{}
+		if request := requestNames[failure.ExecutionId]; request != "" {
+			fields["request"] = request
+		}
+		if name != "" {
+			fields["failure"] = name
+		}
+		messages = append(messages, action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Error),
+			Message: message,
+			Fields:  new(fields),
+		})
+	}
+
+	stats := run.Stats
+	messages = append(messages, action_kit_api.Message{
+		Level: extutil.Ptr(action_kit_api.Info),
+		Message: fmt.Sprintf("Monitor run %s: %d of %d requests failed, %d of %d assertions failed",
+			run.Info.Status, stats.Requests.Failed, stats.Requests.Total, stats.Assertions.Failed, stats.Assertions.Total),
+	})
+
+	switch {
+	case stats.Assertions.Failed > 0:
+		return &action_kit_api.ActionKitError{
+			Status: extutil.Ptr(action_kit_api.Failed),
+			Title:  fmt.Sprintf("%d assertions failed", stats.Assertions.Failed),
+		}, messages
+	case stats.Requests.Failed > 0:
+		return &action_kit_api.ActionKitError{
+			Status: extutil.Ptr(action_kit_api.Failed),
+			Title:  fmt.Sprintf("%d requests failed", stats.Requests.Failed),
+		}, messages
+	case run.Info.Status == monitorRunStatusFailed:
+		return &action_kit_api.ActionKitError{
+			Status: extutil.Ptr(action_kit_api.Failed),
+			Title:  "Monitor run failed",
+		}, messages
+	case run.Info.Status != monitorRunStatusSuccess:
+		return &action_kit_api.ActionKitError{
+			Status: extutil.Ptr(action_kit_api.Errored),
+			Title:  fmt.Sprintf("Monitor run finished with status %q", run.Info.Status),
+		}, messages
+	}
+	return nil, messages
+}
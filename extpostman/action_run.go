@@ -5,14 +5,15 @@ package extpostman
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
 	"github.com/steadybit/action-kit/go/action_kit_sdk"
@@ -22,6 +23,7 @@ import (
 	"github.com/steadybit/extension-kit/extconversion"
 	"github.com/steadybit/extension-kit/extfile"
 	"github.com/steadybit/extension-kit/extutil"
+	"github.com/steadybit/extension-postman/v2/config"
 )
 
 type PostmanAction struct {
@@ -33,18 +35,64 @@ type PostmanState struct {
 	CmdStateID      string   `json:"cmdStateId"`
 	WorkDir         string   `json:"workDir"`
 	StdOutLineCount int      `json:"stdOutLineCount"`
+	// RunId identifies the run in the run history.
+	RunId               string     `json:"runId"`
+	CollectionId        string     `json:"collectionId"`
+	CollectionName      string     `json:"collectionName"`
+	EnvironmentIdOrName string     `json:"environmentIdOrName"`
+	StartedAt           *time.Time `json:"startedAt,omitempty"`
+	// Attempt is the 1-based number of the newman run currently executing.
+	Attempt      int    `json:"attempt"`
+	MaxAttempts  int    `json:"maxAttempts"`
+	RetryBackoff int    `json:"retryBackoff"`
+	RetryMode    string `json:"retryMode"`
+	// RetryCommand is the command of the next attempt, NextAttemptAt the time it is started at.
+	RetryCommand  []string   `json:"retryCommand,omitempty"`
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+	// InitialFailures are the failures of the first attempt, used to report which went away on retry.
+	InitialFailures []string `json:"initialFailures,omitempty"`
+	ArtifactFormat  string   `json:"artifactFormat"`
+	// IncludeResponseBodies also records the full report, which holds the values to redact.
+	IncludeResponseBodies bool `json:"includeResponseBodies"`
+	// Output is the state of the parser turning the newman output of the current attempt into messages.
+	Output NewmanOutputState `json:"output"`
+	// Matrix holds the runs of an environment matrix, one per environment, executed one after
+	// another. MatrixIndex is the run currently executing.
+	Matrix      []PostmanState `json:"matrix,omitempty"`
+	MatrixIndex int            `json:"matrixIndex,omitempty"`
+	// Environment labels the messages and artifacts of a run of an environment matrix, Verdict and
+	// VerdictTitle are its result once finished.
+	Environment  string `json:"environment,omitempty"`
+	Verdict      string `json:"verdict,omitempty"`
+	VerdictTitle string `json:"verdictTitle,omitempty"`
 }
 
 type PostmanConfig struct {
-	EnvironmentIdOrName string
-	Environment         []map[string]string
-	Verbose             bool
-	Bail                bool
-	Timeout             int
-	TimeoutRequest      int
-	Iterations          int
+	EnvironmentIdOrName   string
+	EnvironmentIdsOrNames []string
+	Environment           []map[string]string
+	SecretEnvironment     []map[string]string
+	Verbose               bool
+	Bail                  bool
+	Timeout               int
+	TimeoutRequest        int
+	Iterations            int
+	Retries               int
+	RetryBackoff          int
+	RetryMode             string
+	ArtifactFormat        string
+	IncludeResponseBodies bool
+	UnresolvedVariables   string
 }
 
+const (
+	retryModeFailedRequests = "failed-requests"
+	retryModeCollection     = "collection"
+
+	resultSummaryFile = "result-summary.json"
+	resultHtmlFile    = "result.html"
+)
+
 func NewPostmanAction() action_kit_sdk.Action[PostmanState] {
 	return PostmanAction{}
 }
@@ -62,7 +110,7 @@ func (f PostmanAction) Describe() action_kit_api.ActionDescription {
 	return action_kit_api.ActionDescription{
 		Id:          targetID + ".run",
 		Label:       "Postman",
-		Description: "Integrate a Postman Collection via Postman Cloud API.",
+		Description: "Run a Postman Collection from the Postman Cloud API, a directory, a git repository, a URL or an OpenAPI specification with newman.",
 		Version:     extbuild.GetSemverVersionStringOrUnknown(),
 		Kind:        action_kit_api.Check,
 		Icon:        new(icon),
@@ -100,6 +148,23 @@ func (f PostmanAction) Describe() action_kit_api.ActionDescription {
 				Description: new("UID or unique Name of the Postman Environment"),
 				Required:    new(false),
 				Type:        action_kit_api.ActionParameterTypeString,
+				Options: new([]action_kit_api.ParameterOption{
+					action_kit_api.ParameterOptionsFromTargetAttribute{Attribute: attributeEnvironmentName},
+				}),
+				// environments of sources other than the Postman API are not discovered
+				OptionsOnly: new(false),
+			},
+			{
+				Name:        "environmentIdsOrNames",
+				Label:       "Environment Matrix",
+				Description: new("UIDs or unique Names of further Postman Environments. The collection is run once per environment, one after another, and the step fails if it fails in any of them."),
+				Required:    new(false),
+				Type:        action_kit_api.ActionParameterTypeStringArray,
+				Options: new([]action_kit_api.ParameterOption{
+					action_kit_api.ParameterOptionsFromTargetAttribute{Attribute: attributeEnvironmentName},
+				}),
+				OptionsOnly: new(false),
+				Advanced:    new(true),
 			},
 			{
 				Name:        "environment",
@@ -109,6 +174,14 @@ func (f PostmanAction) Describe() action_kit_api.ActionDescription {
 				Type:        action_kit_api.ActionParameterTypeKeyValue,
 				Advanced:    new(true),
 			},
+			{
+				Name:        "secretEnvironment",
+				Label:       "Secret environment variables",
+				Description: new("Environment variables which will be passed to your Postman Collection as secrets. Their values are masked in all messages and reports."),
+				Required:    new(false),
+				Type:        action_kit_api.ActionParameterTypeKeyValue,
+				Advanced:    new(true),
+			},
 			{
 				Name:         "iterations",
 				Label:        "Iterations",
@@ -150,6 +223,98 @@ func (f PostmanAction) Describe() action_kit_api.ActionDescription {
 				Type:        action_kit_api.ActionParameterTypeBoolean,
 				Advanced:    new(true),
 			},
+			{
+				Name:        "includeResponseBodies",
+				Label:       "Include Response Bodies",
+				Description: new("Show the response bodies in the reports. Configured headers (by default Authorization, Cookie and Set-Cookie) and body fields are redacted in all reports."),
+				Required:    new(false),
+				Type:        action_kit_api.ActionParameterTypeBoolean,
+				Advanced:    new(true),
+			},
+			{
+				Name:         "retries",
+				Label:        "Retries",
+				Description:  new("Number of times a failed collection run is repeated before the check fails. Use this to tolerate known flakiness of external dependencies."),
+				Required:     new(false),
+				Type:         action_kit_api.ActionParameterTypeInteger,
+				DefaultValue: new("0"),
+				MinValue:     new(0),
+				Advanced:     new(true),
+			},
+			{
+				Name:         "retryBackoff",
+				Label:        "Retry Backoff",
+				Description:  new("The time to wait before the first retry. The backoff doubles with every further retry."),
+				Required:     new(false),
+				Type:         action_kit_api.ActionParameterTypeDuration,
+				DefaultValue: new("5s"),
+				Advanced:     new(true),
+			},
+			{
+				Name:         "retryMode",
+				Label:        "Retry Mode",
+				Description:  new("Whether a retry re-runs only the failed requests or the whole collection. Failed requests whose names are not unique in the collection are retried with the whole collection."),
+				Required:     new(false),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new(retryModeFailedRequests),
+				Options: new([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{
+						Label: "Failed requests",
+						Value: retryModeFailedRequests,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Whole collection",
+						Value: retryModeCollection,
+					},
+				}),
+				Advanced: new(true),
+			},
+			{
+				Name:         "unresolvedVariables",
+				Label:        "Unresolved Variables",
+				Description:  new("What to do if requests reference {{variables}} that neither the collection, its folders, the environment, the environment variables nor a script define."),
+				Required:     new(false),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new(unresolvedVariablesWarn),
+				Options: new([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{
+						Label: "Fail the step",
+						Value: unresolvedVariablesFail,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Warn",
+						Value: unresolvedVariablesWarn,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Ignore",
+						Value: unresolvedVariablesIgnore,
+					},
+				}),
+				Advanced: new(true),
+			},
+			{
+				Name:         "artifactFormat",
+				Label:        "Artifact Format",
+				Description:  new("Attach the JSON and HTML reports and the newman log as individual artifacts, or all outputs of the run (reports, JUnit, exported variables and logs) as one compressed artifact."),
+				Required:     new(false),
+				Type:         action_kit_api.ActionParameterTypeString,
+				DefaultValue: new(artifactFormatFiles),
+				Options: new([]action_kit_api.ParameterOption{
+					action_kit_api.ExplicitParameterOption{
+						Label: "Individual reports",
+						Value: artifactFormatFiles,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Zip archive",
+						Value: artifactFormatZip,
+					},
+					action_kit_api.ExplicitParameterOption{
+						Label: "Tar.gz archive",
+						Value: artifactFormatTarGz,
+					},
+				}),
+				Advanced: new(true),
+			},
 		},
 		Prepare: action_kit_api.MutatingEndpointReference{},
 		Start:   action_kit_api.MutatingEndpointReference{},
@@ -158,13 +323,13 @@ func (f PostmanAction) Describe() action_kit_api.ActionDescription {
 	}
 }
 
-func (f PostmanAction) Prepare(_ context.Context, state *PostmanState, raw action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
+func (f PostmanAction) Prepare(ctx context.Context, state *PostmanState, raw action_kit_api.PrepareActionRequestBody) (*action_kit_api.PrepareResult, error) {
 	var request PostmanConfig
 	if err := extconversion.Convert(raw.Config, &request); err != nil {
 		return nil, extension_kit.ToError("Failed to unmarshal the config.", err)
 	}
 
-	collectionIds := raw.Target.Attributes["postman.collection.id"]
+	collectionIds := raw.Target.Attributes[attributeCollectionId]
 	if len(collectionIds) == 0 {
 		return nil, extension_kit.ToError("No collection id provided", nil)
 	}
@@ -172,6 +337,14 @@ func (f PostmanAction) Prepare(_ context.Context, state *PostmanState, raw actio
 		return nil, extension_kit.ToError("More than one collection id provided", nil)
 	}
 	var collectionId = collectionIds[0]
+	state.CollectionId = collectionId
+	if names := raw.Target.Attributes[attributeCollectionName]; len(names) > 0 {
+		state.CollectionName = names[0]
+	}
+	state.RunId = raw.ExecutionId.String()
+	if raw.ExecutionId == uuid.Nil {
+		state.RunId = uuid.NewString()
+	}
 
 	workDir, err := os.MkdirTemp("", "steadybit-postman-*")
 	if err != nil {
@@ -185,23 +358,82 @@ func (f PostmanAction) Prepare(_ context.Context, state *PostmanState, raw actio
 		}
 	}()
 
+	source, err := getCollectionSource(raw.Target.Attributes)
+	if err != nil {
+		return nil, extension_kit.ToError("Failed to find the source of the collection.", err)
+	}
 	collectionFile := filepath.Join(workDir, "collection.json")
-	if err := DownloadCollection(collectionId, collectionFile); err != nil {
+	messages, err := source.fetchCollection(ctx, raw.Target.Attributes, collectionFile)
+	if err != nil {
 		return nil, extension_kit.ToError("Failed to download collection.", err)
 	}
+	if err := validateCollectionFile(collectionFile); err != nil {
+		return nil, extension_kit.ToError(fmt.Sprintf("Collection %s is invalid.", collectionId), err)
+	}
+	if _, err := compileSecretMaskPatterns(config.Config.SecretMaskPatterns); err != nil {
+		return nil, extension_kit.ToError("Invalid secret mask configuration.", err)
+	}
+	if request.IncludeResponseBodies {
+		if _, err := newConfiguredRedactor(); err != nil {
+			return nil, extension_kit.ToError("Invalid redaction configuration.", err)
+		}
+	}
 
+	environments := getEnvironments(request)
+	if len(environments) > 1 {
+		matrixMessages, err := prepareEnvironmentMatrix(ctx, state, source, raw.Target.Attributes, request, collectionFile, environments)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, matrixMessages...)
+	} else {
+		if len(environments) == 1 {
+			state.EnvironmentIdOrName = environments[0]
+		}
+		runMessages, err := prepareRun(ctx, state, source, raw.Target.Attributes, request, collectionFile)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, runMessages...)
+	}
+	prepareSucceeded = true
+	if len(messages) > 0 {
+		return &action_kit_api.PrepareResult{Messages: new(messages)}, nil
+	}
+	return nil, nil
+}
+
+// prepareRun builds the newman command running the collection in the environment of the run,
+// with all outputs written to the working directory of the run.
+func prepareRun(ctx context.Context, state *PostmanState, source collectionSource, attributes map[string][]string, request PostmanConfig, collectionFile string) ([]action_kit_api.Message, error) {
+	var messages []action_kit_api.Message
+	workDir := state.WorkDir
 	state.Command = []string{"newman", "run", collectionFile}
 
-	if request.EnvironmentIdOrName != "" {
-		environmentId, err := GetPostEnvironmentId(request.EnvironmentIdOrName)
+	if state.EnvironmentIdOrName != "" {
+		environmentMessages, err := source.fetchEnvironment(ctx, attributes, state.EnvironmentIdOrName, filepath.Join(workDir, environmentFile))
 		if err != nil {
-			return nil, extension_kit.ToError("Failed to get environment id.", err)
-		}
-		environmentFile := filepath.Join(workDir, "environment.json")
-		if err := DownloadEnvironment(environmentId, environmentFile); err != nil {
 			return nil, extension_kit.ToError("Failed to download environment.", err)
 		}
-		state.Command = append(state.Command, "--environment", environmentFile)
+		messages = append(messages, environmentMessages...)
+	}
+	if len(request.SecretEnvironment) > 0 {
+		if err := addSecretEnvironmentValues(filepath.Join(workDir, environmentFile), request.SecretEnvironment); err != nil {
+			return nil, extension_kit.ToError("Failed to add secret environment variables.", err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(workDir, environmentFile)); err == nil {
+		if err := validateEnvironmentFile(filepath.Join(workDir, environmentFile)); err != nil {
+			return nil, extension_kit.ToError("Environment is invalid.", err)
+		}
+		state.Command = append(state.Command, "--environment", filepath.Join(workDir, environmentFile))
+		shortSecretsMessage, err := getShortSecretsMessage(filepath.Join(workDir, environmentFile))
+		if err != nil {
+			return nil, extension_kit.ToError("Failed to read the secret environment variables.", err)
+		}
+		if shortSecretsMessage != nil {
+			messages = append(messages, *shortSecretsMessage)
+		}
 	}
 	if request.Environment != nil {
 		for _, value := range request.Environment {
@@ -209,6 +441,27 @@ func (f PostmanAction) Prepare(_ context.Context, state *PostmanState, raw actio
 			state.Command = append(state.Command, fmt.Sprintf("%s=%s", value["key"], value["value"]))
 		}
 	}
+	if request.UnresolvedVariables != unresolvedVariablesIgnore {
+		var names []string
+		for _, value := range request.Environment {
+			names = append(names, value["key"])
+		}
+		unresolved, err := findUnresolvedVariables(collectionFile, filepath.Join(workDir, environmentFile), names)
+		if err != nil {
+			return nil, extension_kit.ToError("Failed to check the collection for unresolved variables.", err)
+		}
+		if len(unresolved) > 0 {
+			message := fmt.Sprintf("%d variables are not defined: %s", len(unresolved), describeUnresolvedVariables(unresolved))
+			if request.UnresolvedVariables == unresolvedVariablesFail {
+				return nil, extension_kit.ToError(message, nil)
+			}
+			log.Warn().Msg(message)
+			messages = append(messages, action_kit_api.Message{
+				Level:   extutil.Ptr(action_kit_api.Warn),
+				Message: message,
+			})
+		}
+	}
 	if request.Verbose {
 		state.Command = append(state.Command, "--verbose")
 	}
@@ -222,45 +475,106 @@ func (f PostmanAction) Prepare(_ context.Context, state *PostmanState, raw actio
 		state.Command = append(state.Command, "--timeout-request", fmt.Sprintf("%d", request.TimeoutRequest))
 	}
 
+	reporters := "cli,json-summary,htmlextra,junit"
+	if request.IncludeResponseBodies {
+		reporters += ",json"
+	}
 	state.Command = append(state.Command,
-		"--reporters", "cli,json-summary,htmlextra",
-		"--reporter-summary-json-export", filepath.Join(workDir, "result-summary.json"),
-		"--reporter-htmlextra-export", filepath.Join(workDir, "result.html"),
-		"--reporter-htmlextra-omitResponseBodies",
+		"--reporters", reporters,
+		"--reporter-summary-json-export", filepath.Join(workDir, resultSummaryFile),
+		"--reporter-htmlextra-export", filepath.Join(workDir, resultHtmlFile),
+		"--reporter-junit-export", filepath.Join(workDir, resultJunitFile),
+		"--export-environment", filepath.Join(workDir, exportedEnvironmentFile),
+		"--export-globals", filepath.Join(workDir, exportedGlobalsFile),
 	)
+	if request.IncludeResponseBodies {
+		state.Command = append(state.Command, "--reporter-json-export", filepath.Join(workDir, resultFullFile))
+	} else {
+		state.Command = append(state.Command, "--reporter-htmlextra-omitResponseBodies")
+	}
+	state.IncludeResponseBodies = request.IncludeResponseBodies
 
 	if request.Iterations > 1 {
 		state.Command = append(state.Command, "-n", fmt.Sprintf("%d", request.Iterations))
 	}
+
+	state.MaxAttempts = 1 + max(request.Retries, 0)
+	state.RetryBackoff = request.RetryBackoff
+	state.RetryMode = request.RetryMode
+	state.ArtifactFormat = request.ArtifactFormat
 	log.Info().Msgf("Prepared action. Command: %s", strings.Join(state.Command, " "))
-	prepareSucceeded = true
-	return nil, nil
+	return messages, nil
 }
 
 func (f PostmanAction) Start(_ context.Context, state *PostmanState) (*action_kit_api.StartResult, error) {
+	if len(state.Matrix) > 0 {
+		return startEnvironmentMatrix(state)
+	}
 	log.Info().Msgf("Starting newman!")
-	cmd := exec.Command(state.Command[0], state.Command[1:]...)
+	if err := startNewman(state, state.Command); err != nil {
+		return nil, new(extension_kit.ToError("Failed to start command.", err))
+	}
+	metricActiveRuns.Inc()
+	log.Info().Msgf("Started extension-postman")
+	state.StartedAt = new(time.Now())
+
+	// the command is only needed again if the collection is re-run on failure
+	if state.MaxAttempts <= 1 {
+		state.Command = nil
+	}
+	return nil, nil
+}
+
+// startNewman launches the given newman command as the next attempt of the run. The command
+// state of the previous attempt is replaced only once the command started, so a run that could
+// not be started has no command state and is not recorded.
+func startNewman(state *PostmanState, command []string) error {
+	cmd := exec.Command(command[0], command[1:]...)
 	cmdState := extcmd.NewCmdState(cmd)
-	state.CmdStateID = cmdState.Id
 	err := cmd.Start()
 	if err != nil {
-		return nil, new(extension_kit.ToError("Failed to start command.", err))
+		extcmd.RemoveCmdState(cmdState.Id)
+		return err
 	}
 
+	if state.CmdStateID != "" {
+		extcmd.RemoveCmdState(state.CmdStateID)
+	}
+	state.CmdStateID = cmdState.Id
 	state.Pid = cmd.Process.Pid
+	state.Attempt++
+	state.Output = NewmanOutputState{}
 	go func() {
 		cmdErr := cmdState.Wait()
 		if cmdErr != nil {
 			log.Error().Msgf("Failed to execute postman action: %s", cmdErr)
 		}
 	}()
-	log.Info().Msgf("Started extension-postman")
-
-	state.Command = nil
-	return nil, nil
+	return nil
 }
 
 func (f PostmanAction) Status(_ context.Context, state *PostmanState) (*action_kit_api.StatusResult, error) {
+	if len(state.Matrix) > 0 {
+		return environmentMatrixStatus(state)
+	}
+	return runStatus(state)
+}
+
+// runStatus checks the newman run of the state and starts the next attempt once the backoff of
+// a scheduled retry elapsed.
+func runStatus(state *PostmanState) (*action_kit_api.StatusResult, error) {
+	if state.NextAttemptAt != nil {
+		if time.Now().Before(*state.NextAttemptAt) {
+			return &action_kit_api.StatusResult{Completed: false}, nil
+		}
+		log.Info().Msgf("Starting attempt %d of %d", state.Attempt+1, state.MaxAttempts)
+		if err := startNewman(state, state.RetryCommand); err != nil {
+			return nil, new(extension_kit.ToError("Failed to start retry of the collection run.", err))
+		}
+		state.NextAttemptAt = nil
+		state.RetryCommand = nil
+	}
+
 	log.Info().Msgf("Checking collection run status for %d\n", state.Pid)
 
 	cmdState, err := extcmd.GetCmdState(state.CmdStateID)
@@ -269,6 +583,7 @@ func (f PostmanAction) Status(_ context.Context, state *PostmanState) (*action_k
 	}
 
 	var result action_kit_api.StatusResult
+	var verdictMessages []action_kit_api.Message
 
 	// check if postman is still running
 	exitCode := cmdState.ExitCode()
@@ -284,27 +599,36 @@ func (f PostmanAction) Status(_ context.Context, state *PostmanState) (*action_k
 				Title:  "Postman process is not running anymore.",
 			}
 			result.Completed = true
+		} else {
+			result.Completed = false
 		}
-		result.Completed = false
 	} else if exitCode == 0 {
 		log.Info().Msgf("Postman run completed successfully")
 		result.Completed = true
+		verdictMessages = getRetryOutcomeMessages(state, nil)
 	} else {
+		// check if summary file and try to check if it is a failure
+		report, err := readNewmanReport(filepath.Join(state.WorkDir, resultSummaryFile))
+		if err != nil {
+			return nil, new(extension_kit.ToError("Failed to parse report json", err))
+		}
+
+		if state.Attempt < state.MaxAttempts {
+			messages, err := maskSecretMessages(state, append(getStdOutMessages(state, readOutput(state, cmdState, true)), scheduleRetry(state, report, exitCode)...))
+			if err != nil {
+				return nil, new(extension_kit.ToError("Failed to mask secrets in messages", err))
+			}
+			return &action_kit_api.StatusResult{
+				Completed: false,
+				Messages:  new(messages),
+			}, nil
+		}
+
 		result.Error = &action_kit_api.ActionKitError{
 			Status: extutil.Ptr(action_kit_api.Errored),
 			Title:  fmt.Sprintf("Postman run failed, exit-code %d", exitCode),
 		}
-
-		// check if summary file and try to check if it is a failure
-		resultSummaryFileName := filepath.Join(state.WorkDir, "result-summary.json")
-		_, err = os.Stat(resultSummaryFileName)
-		if err == nil { // file exists
-			byteValue, _ := os.ReadFile(resultSummaryFileName)
-			var report NewmanJsonReport
-			jsonErr := json.Unmarshal(byteValue, &report)
-			if jsonErr != nil {
-				return nil, new(extension_kit.ToError("Failed to parse report json", err))
-			}
+		if report != nil {
 			if report.Run.Stats.Assertions != nil && report.Run.Stats.Assertions.Failed > 0 {
 				result.Error = &action_kit_api.ActionKitError{
 					Status: extutil.Ptr(action_kit_api.Failed),
@@ -317,28 +641,160 @@ func (f PostmanAction) Status(_ context.Context, state *PostmanState) (*action_k
 				}
 			}
 		}
+		verdictMessages = getRetryOutcomeMessages(state, report)
 
 		result.Completed = true
 	}
 
-	messages := getStdOutMessages(cmdState.GetLines(false))
+	messages, err := maskSecretMessages(state, append(getStdOutMessages(state, readOutput(state, cmdState, false)), verdictMessages...))
+	if err != nil {
+		return nil, new(extension_kit.ToError("Failed to mask secrets in messages", err))
+	}
 	log.Debug().Msgf("Returning %d messages", len(messages))
 
 	result.Messages = new(messages)
 	return &result, nil
 }
 
-func getStdOutMessages(lines []string) []action_kit_api.Message {
-	messages := make([]action_kit_api.Message, 0)
-	for _, line := range lines {
+// scheduleRetry archives the reports of the failed attempt and prepares the command of the
+// next attempt, which Status starts once the backoff has elapsed.
+func scheduleRetry(state *PostmanState, report *NewmanJsonReport, exitCode int) []action_kit_api.Message {
+	if state.Attempt == 1 {
+		state.InitialFailures = report.FailureDescriptions()
+	}
+	archiveAttemptReports(state.WorkDir, state.Attempt)
+
+	state.RetryCommand = state.Command
+	failedRequests := report.FailedRequestNames()
+	var ambiguous []string
+	if state.RetryMode != retryModeCollection && len(failedRequests) > 0 {
+		ambiguous = ambiguousItemNames(state.Command[2], failedRequests)
+		if len(ambiguous) == 0 {
+			state.RetryCommand = append([]string{}, state.Command...)
+			for _, name := range failedRequests {
+				state.RetryCommand = append(state.RetryCommand, "--folder", name)
+			}
+		}
+	}
+
+	backoff := (time.Duration(state.RetryBackoff) * time.Millisecond) << (state.Attempt - 1)
+	state.NextAttemptAt = new(time.Now().Add(backoff))
+
+	message := fmt.Sprintf("Attempt %d of %d failed with exit code %d, retrying the whole collection in %s", state.Attempt, state.MaxAttempts, exitCode, backoff)
+	if len(state.RetryCommand) > len(state.Command) {
+		message = fmt.Sprintf("Attempt %d of %d failed with exit code %d, retrying %d failed requests in %s", state.Attempt, state.MaxAttempts, exitCode, len(failedRequests), backoff)
+	} else if len(ambiguous) > 0 {
+		message += fmt.Sprintf(", as the collection has several items named %s", strings.Join(ambiguous, ", "))
+	}
+	log.Info().Msg(message)
+	return []action_kit_api.Message{
+		{
+			Level:   extutil.Ptr(action_kit_api.Warn),
+			Message: message,
+		},
+	}
+}
+
+// ambiguousItemNames returns the names that several items of the collection share. newman's
+// --folder selects items by name only, so a retry of these requests could run the wrong ones.
+func ambiguousItemNames(collectionPath string, names []string) []string {
+	collection, err := readJsonObject(collectionPath)
+	if err != nil {
+		log.Warn().Msgf("Failed to check the collection for duplicate request names: %s", err)
+		return nil
+	}
+	counts := make(map[string]int)
+	countItemNames(collection["item"], counts)
+	var ambiguous []string
+	for _, name := range names {
+		if counts[name] > 1 {
+			ambiguous = append(ambiguous, fmt.Sprintf("%q", name))
+		}
+	}
+	return ambiguous
+}
+
+func countItemNames(value any, counts map[string]int) {
+	items, _ := value.([]any)
+	for _, value := range items {
+		item, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		if name, ok := item["name"].(string); ok {
+			counts[name]++
+		}
+		countItemNames(item["item"], counts)
+	}
+}
+
+// archiveAttemptReports renames the reports of the given attempt, so the next attempt does not
+// overwrite them and all attempts can be attached as artifacts.
+func archiveAttemptReports(workDir string, attempt int) {
+	for _, fileName := range reportFiles {
+		source := filepath.Join(workDir, fileName)
+		if _, err := os.Stat(source); err != nil {
+			continue
+		}
+		if err := os.Rename(source, filepath.Join(workDir, attemptFileName(fileName, attempt))); err != nil {
+			log.Warn().Msgf("Failed to archive report %s of attempt %d: %s", fileName, attempt, err)
+		}
+	}
+}
+
+// attemptFileName returns the name of an archived report, e.g. result.attempt-1.html.
+func attemptFileName(fileName string, attempt int) string {
+	extension := filepath.Ext(fileName)
+	return fmt.Sprintf("%s.attempt-%d%s", strings.TrimSuffix(fileName, extension), attempt, extension)
+}
+
+// getRetryOutcomeMessages reports which failures of the first attempt went away on retry. The
+// report of the last attempt is nil if it succeeded.
+func getRetryOutcomeMessages(state *PostmanState, finalReport *NewmanJsonReport) []action_kit_api.Message {
+	if state.Attempt <= 1 || len(state.InitialFailures) == 0 {
+		return nil
+	}
+
+	remaining := make(map[string]bool)
+	for _, failure := range finalReport.FailureDescriptions() {
+		remaining[failure] = true
+	}
+	var recovered, persistent []string
+	for _, failure := range state.InitialFailures {
+		if remaining[failure] {
+			persistent = append(persistent, failure)
+		} else {
+			recovered = append(recovered, failure)
+		}
+	}
+
+	var messages []action_kit_api.Message
+	if len(recovered) > 0 {
 		messages = append(messages, action_kit_api.Message{
-			Level:   extutil.Ptr(action_kit_api.Info),
-			Message: line,
+			Level:   extutil.Ptr(action_kit_api.Warn),
+			Message: fmt.Sprintf("%d failures went away on retry after %d attempts: %s", len(recovered), state.Attempt, strings.Join(recovered, "; ")),
+		})
+	}
+	if len(persistent) > 0 {
+		messages = append(messages, action_kit_api.Message{
+			Level:   extutil.Ptr(action_kit_api.Error),
+			Message: fmt.Sprintf("%d failures persisted over %d attempts: %s", len(persistent), state.Attempt, strings.Join(persistent, "; ")),
 		})
 	}
 	return messages
 }
 
+// readOutput returns the new output lines of newman and keeps them in the run's log file.
+func readOutput(state *PostmanState, cmdState *extcmd.CmdState, includePartialLines bool) []string {
+	lines := cmdState.GetLines(includePartialLines)
+	if len(lines) > 0 {
+		if err := extfile.AppendToFile(filepath.Join(state.WorkDir, newmanLogFile), lines); err != nil {
+			log.Warn().Msgf("Failed to write newman output to log file: %s", err)
+		}
+	}
+	return lines
+}
+
 func (f PostmanAction) Stop(_ context.Context, state *PostmanState) (*action_kit_api.StopResult, error) {
 	// os.RemoveAll("") is a no-op, so this is safe even if Prepare never set WorkDir.
 	defer func() {
@@ -347,22 +803,48 @@ func (f PostmanAction) Stop(_ context.Context, state *PostmanState) (*action_kit
 		}
 	}()
 
+	if len(state.Matrix) > 0 {
+		return stopEnvironmentMatrix(state)
+	}
+	if state.CmdStateID == "" {
+		// newman was never started
+		return nil, nil
+	}
+	summary, artifacts, messages, err := stopRun(state)
+	metricActiveRuns.Dec()
+	if summary != nil {
+		recordRun(*summary)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &action_kit_api.StopResult{
+		Artifacts: new(artifacts),
+		Messages:  new(messages),
+	}, nil
+}
+
+// stopRun kills newman if it is still running, summarizes the run and collects its outputs. The
+// summary is returned as soon as the result of the run is known, even if collecting the outputs
+// fails.
+func stopRun(state *PostmanState) (*RunSummary, []action_kit_api.Artifact, []action_kit_api.Message, error) {
 	cmdState, err := extcmd.GetCmdState(state.CmdStateID)
 	if err != nil {
-		return nil, new(extension_kit.ToError("Failed to find command state", err))
+		return nil, nil, nil, new(extension_kit.ToError("Failed to find command state", err))
 	}
 	extcmd.RemoveCmdState(state.CmdStateID)
 
-	// kill postman if it is still running
-	var pid = state.Pid
-	process, err := os.FindProcess(pid)
-	if err != nil {
-		return nil, new(extension_kit.ToError("Failed to find process", err))
+	// kill postman if it is still running, the pid of a finished run may have been reused
+	if cmdState.ExitCode() == -1 {
+		process, err := os.FindProcess(state.Pid)
+		if err != nil {
+			return nil, nil, nil, new(extension_kit.ToError("Failed to find process", err))
+		}
+		_ = process.Kill()
 	}
-	_ = process.Kill()
 
 	// read Stout and Stderr and send it as Messages
-	messages := getStdOutMessages(cmdState.GetLines(true))
+	messages := getStdOutMessages(state, readOutput(state, cmdState, true))
 
 	// read return code and send it as Message
 	exitCode := cmdState.ExitCode()
@@ -373,44 +855,22 @@ func (f PostmanAction) Stop(_ context.Context, state *PostmanState) (*action_kit
 		})
 	}
 
-	var summaryFileContent string
-	var htmlResultFileContent string
-
-	artifacts := make([]action_kit_api.Artifact, 0)
-
-	// check if summary file exists and send it as artifact
-	resultSummaryFileName := filepath.Join(state.WorkDir, "result-summary.json")
-	_, err = os.Stat(resultSummaryFileName)
-
-	if err == nil { // file exists
-		summaryFileContent, err = extfile.File2Base64(resultSummaryFileName)
-		if err != nil {
-			return nil, new(extension_kit.ToError("Failed to open summaryFileContent file", err))
-		}
-		artifacts = append(artifacts, action_kit_api.Artifact{
-			Label: "$(experimentKey)_$(executionId)_postman.json",
-			Data:  summaryFileContent,
-		})
+	report, err := readNewmanReport(filepath.Join(state.WorkDir, resultSummaryFile))
+	if err != nil {
+		log.Warn().Msgf("Failed to parse report json: %s", err)
 	}
+	observeNewmanExitCode(exitCode)
+	summary := summarizeRun(state, exitCode, report)
 
-	// check if html result file exists and send it as artifact
-	resultFileName := filepath.Join(state.WorkDir, "result.html")
-	_, err = os.Stat(resultFileName)
-
-	if err == nil { // file exists
-		htmlResultFileContent, err = extfile.File2Base64(resultFileName)
-		if err != nil {
-			return nil, new(extension_kit.ToError("Failed to open htmlResultFileContent file", err))
-		}
-		artifacts = append(artifacts, action_kit_api.Artifact{
-			Label: "$(experimentKey)_$(executionId)_postman.html",
-			Data:  htmlResultFileContent,
-		})
+	artifacts, artifactMessages, err := getArtifacts(state)
+	if err != nil {
+		return &summary, nil, nil, new(extension_kit.ToError("Failed to attach run outputs", err))
+	}
+	messages, err = maskSecretMessages(state, append(messages, artifactMessages...))
+	if err != nil {
+		return &summary, nil, nil, new(extension_kit.ToError("Failed to mask secrets in messages", err))
 	}
 
 	log.Debug().Msgf("Returning %d messages", len(messages))
-	return &action_kit_api.StopResult{
-		Artifacts: new(artifacts),
-		Messages:  new(messages),
-	}, nil
+	return &summary, artifacts, messages, nil
 }
@@ -12,9 +12,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/steadybit/action-kit/go/action_kit_api/v2"
 	"github.com/steadybit/extension-kit/extutil"
 	"github.com/steadybit/extension-postman/v2/config"
@@ -31,7 +33,7 @@ func newPostmanApiStub(t *testing.T) *httptest.Server {
 
 		switch {
 		case strings.HasPrefix(r.URL.Path, "/collections/"):
-			_, _ = w.Write([]byte(`{"collection":{"info":{"name":"test"},"item":[]}}`))
+			_, _ = w.Write([]byte(`{"collection":{"info":{"name":"test","schema":"https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},"item":[{"name":"Get","request":{"method":"GET","url":"https://example.com"}}]}}`))
 		case strings.HasPrefix(r.URL.Path, "/environments/"):
 			_, _ = w.Write([]byte(`{"environment":{"id":"5f757f0d-de24-462c-867f-256bb696d2dd","name":"env","values":[]}}`))
 		default:
@@ -141,3 +143,92 @@ func TestPrepareCollectionRunWithEmptyEnvironment(t *testing.T) {
 	assert.NotContains(t, state.Command, "--environment")
 	assert.Contains(t, state.Command, "--verbose")
 }
+
+func TestScheduleRetryOfFailedRequests(t *testing.T) {
+	workDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, resultSummaryFile), []byte("{}"), 0600))
+	state := PostmanState{
+		Command:      []string{"newman", "run", "collection.json"},
+		WorkDir:      workDir,
+		Attempt:      1,
+		MaxAttempts:  3,
+		RetryBackoff: 1000,
+		RetryMode:    retryModeFailedRequests,
+	}
+	report := &NewmanJsonReport{Run: Run{Failures: []Failure{
+		{Source: &FailureItem{Name: "Get products"}, Error: &FailureError{Test: "Status code is 200"}},
+		{Source: &FailureItem{Name: "Get cart"}, Error: &FailureError{Message: "ETIMEDOUT"}},
+	}}}
+
+	messages := scheduleRetry(&state, report, 1)
+
+	require.Len(t, messages, 1)
+	assert.Equal(t, action_kit_api.Warn, *messages[0].Level)
+	assert.Equal(t, []string{"newman", "run", "collection.json", "--folder", "Get products", "--folder", "Get cart"}, state.RetryCommand)
+	assert.Equal(t, []string{"Get products: Status code is 200", "Get cart: ETIMEDOUT"}, state.InitialFailures)
+	assert.NotNil(t, state.NextAttemptAt)
+	assert.FileExists(t, filepath.Join(workDir, "result-summary.attempt-1.json"))
+	assert.NoFileExists(t, filepath.Join(workDir, resultSummaryFile))
+
+	// the second attempt only fails on one of the two requests
+	state.Attempt = 2
+	outcome := getRetryOutcomeMessages(&state, &NewmanJsonReport{Run: Run{Failures: report.Run.Failures[1:]}})
+	require.Len(t, outcome, 2)
+	assert.Equal(t, action_kit_api.Warn, *outcome[0].Level)
+	assert.Contains(t, outcome[0].Message, "Get products: Status code is 200")
+	assert.Equal(t, action_kit_api.Error, *outcome[1].Level)
+	assert.Contains(t, outcome[1].Message, "Get cart: ETIMEDOUT")
+}
+
+func TestScheduleRetryOfTheWholeCollectionIfFailedRequestNamesAreNotUnique(t *testing.T) {
+	workDir := t.TempDir()
+	collection := filepath.Join(workDir, "collection.json")
+	require.NoError(t, os.WriteFile(collection, []byte(`{"info": {"name": "shop"}, "item": [
+		{"name": "products", "item": [{"name": "Get", "request": "https://example.com/products"}]},
+		{"name": "cart", "item": [{"name": "Get", "request": "https://example.com/cart"}]},
+		{"name": "Get products", "request": "https://example.com/products"}
+	]}`), 0600))
+	state := PostmanState{
+		Command:      []string{"newman", "run", collection},
+		WorkDir:      workDir,
+		Attempt:      1,
+		MaxAttempts:  3,
+		RetryBackoff: 1000,
+		RetryMode:    retryModeFailedRequests,
+	}
+	report := &NewmanJsonReport{Run: Run{Failures: []Failure{
+		{Source: &FailureItem{Name: "Get products"}, Error: &FailureError{Test: "Status code is 200"}},
+		{Source: &FailureItem{Name: "Get"}, Error: &FailureError{Message: "ETIMEDOUT"}},
+	}}}
+
+	messages := scheduleRetry(&state, report, 1)
+
+	require.Len(t, messages, 1)
+	assert.Equal(t, action_kit_api.Warn, *messages[0].Level)
+	assert.Equal(t, "Attempt 1 of 3 failed with exit code 1, retrying the whole collection in 1s, as the collection has several items named \"Get\"", messages[0].Message)
+	assert.Equal(t, state.Command, state.RetryCommand)
+}
+
+func TestFailedStartIsNeitherRecordedNorCountedAsActive(t *testing.T) {
+	store, err := openRunHistoryStore(filepath.Join(t.TempDir(), "runs.db"), 10)
+	require.NoError(t, err)
+	runHistory = store
+	t.Cleanup(func() {
+		runHistory = nil
+		_ = store.close()
+	})
+	active := testutil.ToFloat64(metricActiveRuns)
+	state := &PostmanState{RunId: "4713", WorkDir: t.TempDir(), Command: []string{filepath.Join(t.TempDir(), "newman")}, MaxAttempts: 1}
+
+	_, err = PostmanAction{}.Start(context.Background(), state)
+	require.Error(t, err)
+	assert.Empty(t, state.CmdStateID)
+
+	result, err := PostmanAction{}.Stop(context.Background(), state)
+	require.NoError(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, active, testutil.ToFloat64(metricActiveRuns))
+	summary, err := store.get("4713")
+	require.NoError(t, err)
+	assert.Nil(t, summary)
+}
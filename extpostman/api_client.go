@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// retryPolicy bounds the retries of a Postman API request.
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	// maxRetryAfter is the longest Retry-After that is waited for. Longer waits fail the request,
+	// so runs can fall back to the cache instead.
+	maxRetryAfter time.Duration
+}
+
+// circuitBreakerPolicy configures when requests to a Postman API are paused.
+type circuitBreakerPolicy struct {
+	// failureThreshold is the number of consecutive requests failing despite their retries that
+	// opens the circuit.
+	failureThreshold int
+	openDuration     time.Duration
+}
+
+var (
+	postmanApiRetries        = retryPolicy{maxAttempts: 4, initialBackoff: 500 * time.Millisecond, maxBackoff: 8 * time.Second, maxRetryAfter: time.Minute}
+	postmanApiCircuitBreaker = circuitBreakerPolicy{failureThreshold: 5, openDuration: 30 * time.Second}
+
+	// circuitBreakers holds the circuit breaker of each Postman API by scheme and host.
+	circuitBreakers sync.Map
+
+	errCircuitOpen = errors.New("circuit breaker is open")
+)
+
+// circuitBreaker stops requests to an API after consecutive failures. Once the circuit was open
+// for the open duration, a single request probes whether the API recovered.
+type circuitBreaker struct {
+	mutex     sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func getCircuitBreaker(apiUrl string) *circuitBreaker {
+	breaker, _ := circuitBreakers.LoadOrStore(apiUrl, &circuitBreaker{})
+	return breaker.(*circuitBreaker)
+}
+
+// allow returns whether a request may be sent, and otherwise until when the circuit stays open.
+// While a request probes the API, the circuit stays open for at most another open duration.
+func (b *circuitBreaker) allow() (bool, time.Time) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.failures < postmanApiCircuitBreaker.failureThreshold {
+		return true, time.Time{}
+	}
+	if time.Now().Before(b.openUntil) {
+		return false, b.openUntil
+	}
+	if b.probing {
+		return false, time.Now().Add(postmanApiCircuitBreaker.openDuration)
+	}
+	b.probing = true
+	return true, time.Time{}
+}
+
+// release ends a request that tells nothing about the availability of the API, e.g. because it
+// was aborted.
+func (b *circuitBreaker) release() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.probing = false
+}
+
+func (b *circuitBreaker) record(apiUrl string, success bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.probing = false
+	if success {
+		if b.failures >= postmanApiCircuitBreaker.failureThreshold {
+			log.Info().Msgf("Postman API %s recovered, resuming requests.", apiUrl)
+		}
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= postmanApiCircuitBreaker.failureThreshold {
+		b.openUntil = time.Now().Add(postmanApiCircuitBreaker.openDuration)
+		log.Warn().Msgf("Postman API %s failed %d times in a row, pausing requests for %s.", apiUrl, b.failures, postmanApiCircuitBreaker.openDuration)
+	}
+}
+
+// doPostmanApiRequest sends the request to the Postman API. Requests answered with 429 or a 5xx
+// status code, or failing without response, are retried with exponential backoff and jitter,
+// waiting for the Retry-After of the response if given. Requests that are not idempotent, like
+// running a monitor, are only retried on 429, as the API did not process them then.
+// Consecutive failures open the circuit breaker of the API, which fails further requests
+// immediately until the API recovered. Cancelling the context of the request aborts the retries.
+func doPostmanApiRequest(client *http.Client, req *http.Request) (*http.Response, error) {
+	apiUrl := req.URL.Scheme + "://" + req.URL.Host
+	endpoint := postmanApiEndpoint(req)
+	breaker := getCircuitBreaker(apiUrl)
+	if allowed, openUntil := breaker.allow(); !allowed {
+		metricApiRequests.WithLabelValues(endpoint, "circuit_open").Inc()
+		return nil, fmt.Errorf("%w until %s after repeated failures", errCircuitOpen, openUntil.Format(time.RFC3339))
+	}
+	idempotent := req.Method == http.MethodGet || req.Method == http.MethodHead
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				breaker.release()
+				return nil, err
+			}
+			req.Body = body
+		}
+		started := time.Now()
+		response, err := client.Do(req)
+		observePostmanApiRequest(endpoint, response, err, time.Since(started))
+		if req.Context().Err() != nil {
+			breaker.release()
+			return response, err
+		}
+
+		var wait time.Duration
+		switch {
+		case err != nil && idempotent:
+			wait = backoff(attempt)
+		case err != nil:
+			breaker.record(apiUrl, false)
+			return nil, err
+		case response.StatusCode == http.StatusTooManyRequests:
+			wait = retryAfter(response.Header.Get("Retry-After"), attempt)
+		case response.StatusCode >= 500 && idempotent:
+			wait = retryAfter(response.Header.Get("Retry-After"), attempt)
+		default:
+			breaker.record(apiUrl, response.StatusCode < 500)
+			return response, nil
+		}
+
+		if attempt >= postmanApiRetries.maxAttempts || wait > postmanApiRetries.maxRetryAfter {
+			if response != nil && response.StatusCode == http.StatusTooManyRequests {
+				// rate limited, but not an outage
+				breaker.release()
+			} else {
+				breaker.record(apiUrl, false)
+			}
+			return response, err
+		}
+		if err != nil {
+			log.Warn().Msgf("Failed to request %s from postman api, retrying in %s (attempt %d of %d): %s", req.URL.Path, wait.Round(time.Millisecond), attempt, postmanApiRetries.maxAttempts, err)
+		} else {
+			log.Warn().Msgf("Postman API answered %s for %s, retrying in %s (attempt %d of %d)", response.Status, req.URL.Path, wait.Round(time.Millisecond), attempt, postmanApiRetries.maxAttempts)
+			_, _ = io.Copy(io.Discard, response.Body)
+			_ = response.Body.Close()
+		}
+		if err := sleepContext(req.Context(), wait); err != nil {
+			breaker.release()
+			return nil, err
+		}
+	}
+}
+
+// backoff returns the exponential backoff before the next attempt, with jitter spreading the
+// retries of concurrent requests.
+func backoff(attempt int) time.Duration {
+	wait := min(postmanApiRetries.initialBackoff<<(attempt-1), postmanApiRetries.maxBackoff)
+	return wait/2 + rand.N(wait/2+1)
+}
+
+// retryAfter returns the wait given by the Retry-After header, either in seconds or as HTTP
+// date, and falls back to the backoff without one.
+func retryAfter(header string, attempt int) time.Duration {
+	if header == "" {
+		return backoff(attempt)
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return backoff(attempt)
+}
+
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
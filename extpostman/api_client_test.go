@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/steadybit/extension-postman/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useFastPostmanApiRetries shortens the backoff of retried Postman API requests for the test.
+func useFastPostmanApiRetries(t *testing.T) {
+	t.Helper()
+	retries := postmanApiRetries
+	postmanApiRetries = retryPolicy{maxAttempts: 4, initialBackoff: time.Millisecond, maxBackoff: 5 * time.Millisecond, maxRetryAfter: 2 * time.Second}
+	t.Cleanup(func() { postmanApiRetries = retries })
+}
+
+// newScriptedPostmanApiStub answers the requests with the given status codes one after another,
+// repeating the last one, and counts the requests.
+func newScriptedPostmanApiStub(t *testing.T, requests *atomic.Int32, header http.Header, statusCodes ...int) postmanAccount {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		statusCode := statusCodes[min(int(requests.Add(1)), len(statusCodes))-1]
+		for key, values := range header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(statusCode)
+		if statusCode == http.StatusOK {
+			_, _ = w.Write([]byte(`{"workspaces":[]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return postmanAccount{name: config.DefaultAccount, apiKey: "123456", baseUrl: server.URL}
+}
+
+func TestPostmanApiRequestsAreRetried(t *testing.T) {
+	useFastPostmanApiRetries(t)
+	var requests atomic.Int32
+	account := newScriptedPostmanApiStub(t, &requests, nil, http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK)
+
+	_, err := getPostmanWorkspaces(account)
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestPostmanApiRequestsGiveUpAfterMaxAttempts(t *testing.T) {
+	useFastPostmanApiRetries(t)
+	var requests atomic.Int32
+	account := newScriptedPostmanApiStub(t, &requests, nil, http.StatusInternalServerError)
+
+	_, err := getPostmanWorkspaces(account)
+
+	assert.ErrorContains(t, err, "500")
+	assert.True(t, isPostmanApiUnavailable(err))
+	assert.Equal(t, int32(4), requests.Load())
+}
+
+func TestPostmanApiRequestsHonourRetryAfter(t *testing.T) {
+	useFastPostmanApiRetries(t)
+	var requests atomic.Int32
+	account := newScriptedPostmanApiStub(t, &requests, http.Header{"Retry-After": {"1"}}, http.StatusTooManyRequests, http.StatusOK)
+
+	started := time.Now()
+	_, err := getPostmanWorkspaces(account)
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+	assert.GreaterOrEqual(t, time.Since(started), time.Second)
+}
+
+func TestPostmanApiRequestsFailOnRetryAfterBeyondLimit(t *testing.T) {
+	useFastPostmanApiRetries(t)
+	var requests atomic.Int32
+	account := newScriptedPostmanApiStub(t, &requests, http.Header{"Retry-After": {"3600"}}, http.StatusTooManyRequests)
+
+	_, err := getPostmanWorkspaces(account)
+
+	assert.ErrorContains(t, err, "429")
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestNonIdempotentPostmanApiRequestsAreOnlyRetriedOnRateLimits(t *testing.T) {
+	useFastPostmanApiRetries(t)
+	var requests atomic.Int32
+	account := newScriptedPostmanApiStub(t, &requests, nil, http.StatusTooManyRequests, http.StatusServiceUnavailable)
+
+	_, err := runPostmanMonitor(context.Background(), account, "m1")
+
+	assert.ErrorContains(t, err, "503")
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestPostmanApiRequestsAbortOnCancellation(t *testing.T) {
+	retries := postmanApiRetries
+	postmanApiRetries.initialBackoff = time.Minute
+	postmanApiRetries.maxBackoff = time.Minute
+	t.Cleanup(func() { postmanApiRetries = retries })
+	var requests atomic.Int32
+	account := newScriptedPostmanApiStub(t, &requests, nil, http.StatusServiceUnavailable)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	err := callPostmanApi(postmanHttpClient, ctx, account, http.MethodGet, nil, &PostmanWorkspaceResult{}, workspacesResource)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(started), 10*time.Second)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestCircuitBreakerPausesRequestsDuringOutages(t *testing.T) {
+	useFastPostmanApiRetries(t)
+	policy := postmanApiCircuitBreaker
+	postmanApiCircuitBreaker = circuitBreakerPolicy{failureThreshold: 2, openDuration: 200 * time.Millisecond}
+	t.Cleanup(func() { postmanApiCircuitBreaker = policy })
+	var requests atomic.Int32
+	account := newScriptedPostmanApiStub(t, &requests, nil, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable,
+		http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK)
+
+	for range 2 {
+		_, err := getPostmanWorkspaces(account)
+		assert.ErrorContains(t, err, "503")
+	}
+	assert.Equal(t, int32(8), requests.Load())
+
+	// the open circuit fails requests without sending them
+	_, err := getPostmanWorkspaces(account)
+	assert.ErrorIs(t, err, errCircuitOpen)
+	assert.Regexp(t, `^failed to request workspaces from postman api: circuit breaker is open until \S+ after repeated failures$`, err.Error())
+	assert.True(t, isPostmanApiUnavailable(err))
+	assert.Equal(t, int32(8), requests.Load())
+
+	// once the circuit was open long enough, a request probes the API
+	time.Sleep(250 * time.Millisecond)
+	_, err = getPostmanWorkspaces(account)
+	require.NoError(t, err)
+	_, err = getPostmanWorkspaces(account)
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), requests.Load())
+}
+
+func TestRetryAfter(t *testing.T) {
+	assert.Equal(t, 2*time.Second, retryAfter("2", 1))
+	assert.InDelta(t, float64(30*time.Second), float64(retryAfter(time.Now().Add(30*time.Second).UTC().Format(http.TimeFormat), 1)), float64(time.Second))
+	assert.Equal(t, time.Duration(0), retryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), 1))
+
+	useFastPostmanApiRetries(t)
+	assert.LessOrEqual(t, retryAfter("", 1), time.Millisecond)
+	assert.LessOrEqual(t, retryAfter("soon", 10), 5*time.Millisecond)
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/extension-postman/v2/config"
+)
+
+// rotatedApiKey is the key of the default account last read from PostmanApiKeyFile after
+// startup. It replaces PostmanApiKey for all requests built afterwards, without a restart.
+var rotatedApiKey atomic.Pointer[string]
+
+// getDefaultApiKey returns the current key of the default account.
+func getDefaultApiKey() string {
+	if apiKey := rotatedApiKey.Load(); apiKey != nil {
+		return *apiKey
+	}
+	return config.Config.PostmanApiKey
+}
+
+// InitApiKeyFile watches PostmanApiKeyFile, e.g. a mounted Kubernetes secret, and rotates the
+// key of the default account once the content of the file changes.
+func InitApiKeyFile() {
+	if config.Config.PostmanApiKeyFile == "" {
+		return
+	}
+	interval, err := time.ParseDuration(config.Config.PostmanApiKeyFileCheckInterval)
+	if err != nil || interval <= 0 {
+		log.Error().Msgf("Invalid Postman API key file check interval %q, the key is not reloaded from %s.", config.Config.PostmanApiKeyFileCheckInterval, config.Config.PostmanApiKeyFile)
+		return
+	}
+	watcher := &apiKeyFileWatcher{path: config.Config.PostmanApiKeyFile, seen: config.Config.PostmanApiKey}
+	go func() {
+		// polling also notices the symlink swap Kubernetes uses to update mounted secrets
+		for range time.Tick(interval) {
+			watcher.check()
+		}
+	}()
+	log.Info().Msgf("Reloading the Postman API key from %s every %s.", watcher.path, interval)
+}
+
+type apiKeyFileWatcher struct {
+	path string
+	// seen is the last key read from the file, so a rejected key is not validated again until
+	// the file changes
+	seen string
+}
+
+// check reads the key file and rotates to its key once the Postman API accepts it. If the API
+// rejects the key, the previous key stays in use. The keys themselves are never logged.
+func (w *apiKeyFileWatcher) check() {
+	content, err := os.ReadFile(w.path)
+	if err != nil {
+		log.Warn().Msgf("Failed to read the Postman API key file %s, keeping the current key: %s", w.path, err)
+		return
+	}
+	apiKey := strings.TrimSpace(string(content))
+	if apiKey == w.seen {
+		return
+	}
+	if apiKey == "" {
+		log.Warn().Msgf("The Postman API key file %s is empty, keeping the current key.", w.path)
+		w.seen = apiKey
+		return
+	}
+	if err := validatePostmanApiKey(apiKey); err != nil {
+		if isPostmanApiUnavailable(err) {
+			// retried with the next check, as the key itself may be valid
+			log.Warn().Msgf("Failed to validate the Postman API key of %s, keeping the current key: %s", w.path, err)
+			return
+		}
+		log.Error().Msgf("The Postman API rejected the key of %s, keeping the current key: %s", w.path, err)
+		w.seen = apiKey
+		return
+	}
+	w.seen = apiKey
+	rotatedApiKey.Store(&apiKey)
+	log.Info().Msgf("Rotated the Postman API key of the default account from %s.", w.path)
+}
+
+// validatePostmanApiKey asks the Postman API for the user of the key.
+func validatePostmanApiKey(apiKey string) error {
+	return validatePostmanAccount(context.Background(), postmanAccount{name: config.DefaultAccount, apiKey: apiKey, baseUrl: strings.TrimSuffix(config.Config.PostmanBaseUrl, "/")})
+}
+
+// validatePostmanAccount asks the Postman API for the user of the key of the account, which
+// fails if the key is invalid or the API is unreachable.
+func validatePostmanAccount(ctx context.Context, account postmanAccount) error {
+	var result map[string]any
+	return callPostmanApi(postmanHttpClient, ctx, account, http.MethodGet, nil, &result, "me")
+}
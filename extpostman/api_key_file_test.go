@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steadybit/extension-postman/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApiKeyFileWatcherRotatesToValidKeys(t *testing.T) {
+	useFastPostmanApiRetries(t)
+	unavailable := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case unavailable:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/me" && r.Header.Get("X-API-Key") == "new-key":
+			_, _ = w.Write([]byte(`{"user":{"id":1}}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(server.Close)
+	keyFile := filepath.Join(t.TempDir(), "apiKey")
+	require.NoError(t, os.WriteFile(keyFile, []byte("old-key\n"), 0600))
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_BASE_URL", server.URL)
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_API_KEY_FILE", keyFile)
+	config.ParseConfiguration()
+	t.Cleanup(func() {
+		config.Config.PostmanApiKeyFile = ""
+		rotatedApiKey.Store(nil)
+	})
+	assert.Equal(t, "old-key", getPostmanAccounts()[0].apiKey)
+	watcher := &apiKeyFileWatcher{path: keyFile, seen: config.Config.PostmanApiKey}
+
+	// rejected keys are not used
+	require.NoError(t, os.WriteFile(keyFile, []byte("bad-key"), 0600))
+	watcher.check()
+	assert.Equal(t, "old-key", getPostmanAccounts()[0].apiKey)
+
+	// keys that cannot be validated are retried
+	require.NoError(t, os.WriteFile(keyFile, []byte("new-key\n"), 0600))
+	unavailable = true
+	watcher.check()
+	assert.Equal(t, "old-key", getPostmanAccounts()[0].apiKey)
+
+	unavailable = false
+	watcher.check()
+	assert.Equal(t, "new-key", getPostmanAccounts()[0].apiKey)
+
+	require.NoError(t, os.Remove(keyFile))
+	watcher.check()
+	assert.Equal(t, "new-key", getPostmanAccounts()[0].apiKey)
+}
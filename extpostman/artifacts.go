@@ -0,0 +1,309 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-kit/extutil"
+	"github.com/steadybit/extension-postman/v2/config"
+)
+
+const (
+	artifactFormatFiles = "files"
+	artifactFormatZip   = "zip"
+	artifactFormatTarGz = "tar.gz"
+
+	resultJunitFile         = "result-junit.xml"
+	exportedEnvironmentFile = "exported-environment.json"
+	exportedGlobalsFile     = "exported-globals.json"
+	newmanLogFile           = "newman.log"
+
+	truncationNotice = "[... truncated to fit the maximum artifact size ...]\n"
+)
+
+// artifactLabelPattern matches the characters replaced in the environment names of artifact labels.
+var artifactLabelPattern = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// runOutput is a file a run leaves in its working directory.
+type runOutput struct {
+	fileName string
+	// label is used if the output is attached as an individual artifact
+	label string
+	// report outputs are attached individually unless the outputs are bundled
+	report bool
+	// truncatable outputs are cut down to their tail instead of being dropped if they are too big
+	truncatable bool
+}
+
+type outputContent struct {
+	runOutput
+	data []byte
+}
+
+// reportFiles are the per-attempt outputs of newman, archived by archiveAttemptReports on retries.
+var reportFiles = []string{resultSummaryFile, resultJunitFile, exportedEnvironmentFile, exportedGlobalsFile, resultHtmlFile, resultFullFile}
+
+// getRunOutputs lists the outputs of all attempts of the run in the order of their importance,
+// so the size limit drops the least important ones first.
+func getRunOutputs(state *PostmanState) []runOutput {
+	outputs := []runOutput{
+		{fileName: resultSummaryFile, label: artifactLabel(state, ".json"), report: true},
+		{fileName: resultJunitFile, label: artifactLabel(state, ".xml")},
+		{fileName: exportedEnvironmentFile, label: artifactLabel(state, "_environment.json")},
+		{fileName: exportedGlobalsFile, label: artifactLabel(state, "_globals.json")},
+		{fileName: resultHtmlFile, label: artifactLabel(state, ".html"), report: true},
+		{fileName: newmanLogFile, label: artifactLabel(state, ".log"), report: true, truncatable: true},
+	}
+	for attempt := 1; attempt < state.Attempt; attempt++ {
+		outputs = append(outputs,
+			runOutput{fileName: attemptFileName(resultSummaryFile, attempt), label: artifactLabel(state, fmt.Sprintf("_attempt-%d.json", attempt)), report: true},
+			runOutput{fileName: attemptFileName(resultJunitFile, attempt), label: artifactLabel(state, fmt.Sprintf("_attempt-%d.xml", attempt))},
+			runOutput{fileName: attemptFileName(resultHtmlFile, attempt), label: artifactLabel(state, fmt.Sprintf("_attempt-%d.html", attempt)), report: true},
+		)
+	}
+	return outputs
+}
+
+// artifactLabel returns the label of an output of the run, e.g. $(experimentKey)_$(executionId)_postman.json.
+// The outputs of a run of an environment matrix carry the name of the environment, e.g.
+// $(experimentKey)_$(executionId)_postman_staging-eu.json.
+func artifactLabel(state *PostmanState, suffix string) string {
+	label := "$(experimentKey)_$(executionId)_postman"
+	if state.Environment != "" {
+		label += "_" + strings.Trim(artifactLabelPattern.ReplaceAllString(state.Environment, "-"), "-")
+	}
+	return label + suffix
+}
+
+// getArtifacts attaches the run outputs either as individual report files or as one compressed
+// bundle. Sensitive values and secrets are masked first, then outputs are truncated or dropped
+// until each base64-encoded artifact fits into the configured maximum artifact size; each of
+// these cases is explained by a warning message.
+func getArtifacts(state *PostmanState) ([]action_kit_api.Artifact, []action_kit_api.Message, error) {
+	maxSize := config.Config.MaxArtifactSize
+	bundled := state.ArtifactFormat == artifactFormatZip || state.ArtifactFormat == artifactFormatTarGz
+
+	var outputs []runOutput
+	for _, output := range getRunOutputs(state) {
+		if bundled || output.report {
+			outputs = append(outputs, output)
+		}
+	}
+	contents, err := loadRunOutputs(state.WorkDir, outputs)
+	if err != nil {
+		return nil, nil, err
+	}
+	contents, err = redactOutputs(state, contents)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to redact run outputs: %w", err)
+	}
+	contents, err = maskSecretOutputs(state, contents)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to mask secrets in run outputs: %w", err)
+	}
+
+	artifacts := make([]action_kit_api.Artifact, 0)
+	var messages []action_kit_api.Message
+	if !bundled {
+		// the size limit applies to each individually attached report
+		for _, content := range contents {
+			fitted, fitMessages, err := fitOutputs([]outputContent{content}, maxSize, singleOutput)
+			if err != nil {
+				return nil, nil, err
+			}
+			messages = append(messages, fitMessages...)
+			for _, content := range fitted {
+				artifacts = append(artifacts, action_kit_api.Artifact{
+					Label: content.label,
+					Data:  base64.StdEncoding.EncodeToString(content.data),
+				})
+			}
+		}
+		return artifacts, messages, nil
+	}
+
+	bundle := bundleZip
+	if state.ArtifactFormat == artifactFormatTarGz {
+		bundle = bundleTarGz
+	}
+	contents, messages, err = fitOutputs(contents, maxSize, bundle)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to bundle run outputs: %w", err)
+	}
+	if len(contents) == 0 {
+		return artifacts, messages, nil
+	}
+	data, err := bundle(contents)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to bundle run outputs: %w", err)
+	}
+	artifacts = append(artifacts, action_kit_api.Artifact{
+		Label: artifactLabel(state, "."+state.ArtifactFormat),
+		Data:  base64.StdEncoding.EncodeToString(data),
+	})
+	return artifacts, messages, nil
+}
+
+// loadRunOutputs reads the outputs that exist in the working directory.
+func loadRunOutputs(workDir string, outputs []runOutput) ([]outputContent, error) {
+	var contents []outputContent
+	for _, output := range outputs {
+		data, err := os.ReadFile(filepath.Join(workDir, output.fileName))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", output.fileName, err)
+		}
+		contents = append(contents, outputContent{runOutput: output, data: data})
+	}
+	return contents, nil
+}
+
+// singleOutput is the artifact data of an individually attached output.
+func singleOutput(contents []outputContent) ([]byte, error) {
+	return contents[0].data, nil
+}
+
+// fitOutputs keeps the outputs, in the order of their importance, as long as the base64 encoding
+// of the artifact that encode creates from them stays within maxSize (0 disables the limit).
+// Outputs beyond the limit are truncated to their tail if possible and dropped otherwise.
+func fitOutputs(contents []outputContent, maxSize int64, encode func([]outputContent) ([]byte, error)) ([]outputContent, []action_kit_api.Message, error) {
+	if maxSize <= 0 {
+		return contents, nil, nil
+	}
+	fits := func(candidate []outputContent) (bool, error) {
+		data, err := encode(candidate)
+		if err != nil {
+			return false, err
+		}
+		return int64(base64.StdEncoding.EncodedLen(len(data))) <= maxSize, nil
+	}
+
+	var fitted []outputContent
+	var messages []action_kit_api.Message
+	for _, content := range contents {
+		ok, err := fits(append(slices.Clone(fitted), content))
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			fitted = append(fitted, content)
+			continue
+		}
+
+		if content.truncatable {
+			// binary search for the longest tail that still fits
+			var truncated *outputContent
+			low, high := 1, len(content.data)-1
+			for low <= high {
+				size := (low + high) / 2
+				candidate := content
+				candidate.data = append([]byte(truncationNotice), content.data[len(content.data)-size:]...)
+				ok, err := fits(append(slices.Clone(fitted), candidate))
+				if err != nil {
+					return nil, nil, err
+				}
+				if ok {
+					truncated = &candidate
+					low = size + 1
+				} else {
+					high = size - 1
+				}
+			}
+			if truncated != nil {
+				fitted = append(fitted, *truncated)
+				messages = append(messages, artifactSizeMessage(fmt.Sprintf("%s (%s) exceeds the maximum artifact size of %s and was truncated to its last %s.", content.fileName, formatSize(int64(len(content.data))), formatSize(maxSize), formatSize(int64(len(truncated.data)-len(truncationNotice))))))
+				continue
+			}
+		}
+
+		messages = append(messages, artifactSizeMessage(fmt.Sprintf("%s (%s) does not fit into the maximum artifact size of %s and was dropped.", content.fileName, formatSize(int64(len(content.data))), formatSize(maxSize))))
+	}
+	return fitted, messages, nil
+}
+
+func artifactSizeMessage(message string) action_kit_api.Message {
+	log.Warn().Msg(message)
+	return action_kit_api.Message{
+		Level:   extutil.Ptr(action_kit_api.Warn),
+		Message: message,
+	}
+}
+
+func bundleZip(contents []outputContent) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	for _, content := range contents {
+		entry, err := writer.CreateHeader(&zip.FileHeader{
+			Name:     content.fileName,
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := entry.Write(content.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func bundleTarGz(contents []outputContent) ([]byte, error) {
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, content := range contents {
+		if err := tarWriter.WriteHeader(&tar.Header{
+			Name:    content.fileName,
+			Mode:    0600,
+			Size:    int64(len(content.data)),
+			ModTime: time.Now(),
+		}); err != nil {
+			return nil, err
+		}
+		if _, err := tarWriter.Write(content.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return nil, err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steadybit/extension-postman/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetArtifactsDropsReportsWhoseEncodingExceedsTheMaximumSize(t *testing.T) {
+	config.Config.MaxArtifactSize = 300
+	t.Cleanup(func() { config.Config.MaxArtifactSize = 0 })
+	workDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, resultSummaryFile), []byte(strings.Repeat("s", 200)), 0600))
+	// fits as is, but not base64-encoded
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, resultHtmlFile), []byte(strings.Repeat("h", 250)), 0600))
+
+	artifacts, messages, err := getArtifacts(&PostmanState{WorkDir: workDir, Attempt: 1, ArtifactFormat: artifactFormatFiles})
+
+	require.NoError(t, err)
+	require.Len(t, artifacts, 1)
+	assert.Equal(t, "$(experimentKey)_$(executionId)_postman.json", artifacts[0].Label)
+	assert.LessOrEqual(t, len(artifacts[0].Data), 300)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "result.html (250 B) does not fit into the maximum artifact size of 300 B and was dropped.", messages[0].Message)
+}
+
+func TestGetArtifactsTruncatesTheMaskedLogToFitTheBundle(t *testing.T) {
+	config.Config.MaxArtifactSize = 4000
+	t.Cleanup(func() { config.Config.MaxArtifactSize = 0 })
+	workDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, environmentFile), []byte(`{"values":[{"key":"token","value":"s3cr3t-token","type":"secret","enabled":true}]}`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, resultSummaryFile), []byte(`{"run":{}}`), 0600))
+	random := rand.New(rand.NewPCG(1, 2))
+	var log strings.Builder
+	for range 500 {
+		_, _ = fmt.Fprintf(&log, "GET https://example.com/%x?token=s3cr3t-token\n", random.Uint64())
+	}
+	log.WriteString("last line\n")
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, newmanLogFile), []byte(log.String()), 0600))
+
+	artifacts, messages, err := getArtifacts(&PostmanState{WorkDir: workDir, Attempt: 1, ArtifactFormat: artifactFormatTarGz})
+
+	require.NoError(t, err)
+	require.Len(t, artifacts, 1)
+	assert.LessOrEqual(t, len(artifacts[0].Data), 4000)
+	require.Len(t, messages, 1)
+	assert.Contains(t, messages[0].Message, "newman.log")
+	assert.Contains(t, messages[0].Message, "was truncated to its last")
+
+	data, err := base64.StdEncoding.DecodeString(artifacts[0].Data)
+	require.NoError(t, err)
+	gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	archive := tar.NewReader(gzipReader)
+	files := make(map[string]string)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(archive)
+		require.NoError(t, err)
+		files[header.Name] = string(content)
+	}
+	assert.Equal(t, `{"run":{}}`, files[resultSummaryFile])
+	assert.True(t, strings.HasPrefix(files[newmanLogFile], truncationNotice))
+	assert.True(t, strings.HasSuffix(files[newmanLogFile], "last line\n"))
+	assert.NotContains(t, files[newmanLogFile], "s3cr3t")
+}
+
+func TestGetArtifactsAttachesTheReportsAndTheLogAsFiles(t *testing.T) {
+	config.Config.MaxArtifactSize = 0
+	workDir := t.TempDir()
+	for _, fileName := range []string{resultSummaryFile, resultHtmlFile, resultJunitFile, newmanLogFile} {
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, fileName), []byte(fileName), 0600))
+	}
+
+	artifacts, messages, err := getArtifacts(&PostmanState{WorkDir: workDir, Attempt: 1, ArtifactFormat: artifactFormatFiles})
+
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+	var labels []string
+	for _, artifact := range artifacts {
+		labels = append(labels, artifact.Label)
+	}
+	assert.Equal(t, []string{"$(experimentKey)_$(executionId)_postman.json", "$(experimentKey)_$(executionId)_postman.html", "$(experimentKey)_$(executionId)_postman.log"}, labels)
+}
+
+func TestGetArtifactsAttachesTheTruncatedAndMaskedLogAsFile(t *testing.T) {
+	config.Config.MaxArtifactSize = 400
+	t.Cleanup(func() { config.Config.MaxArtifactSize = 0 })
+	workDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, environmentFile), []byte(`{"values":[{"key":"token","value":"s3cr3t-token","type":"secret","enabled":true}]}`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, newmanLogFile), []byte(strings.Repeat("token=s3cr3t-token\n", 50)+"last line\n"), 0600))
+
+	artifacts, messages, err := getArtifacts(&PostmanState{WorkDir: workDir, Attempt: 1, ArtifactFormat: artifactFormatFiles})
+
+	require.NoError(t, err)
+	require.Len(t, artifacts, 1)
+	assert.Equal(t, "$(experimentKey)_$(executionId)_postman.log", artifacts[0].Label)
+	assert.LessOrEqual(t, len(artifacts[0].Data), 400)
+	data, err := base64.StdEncoding.DecodeString(artifacts[0].Data)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), truncationNotice))
+	assert.True(t, strings.HasSuffix(string(data), "token=***\nlast line\n"))
+	assert.NotContains(t, string(data), "s3cr3t")
+	require.Len(t, messages, 1)
+	assert.Contains(t, messages[0].Message, "newman.log (510 B) exceeds the maximum artifact size of 400 B and was truncated")
+}
+
+func TestGetArtifactsBundlesAllOutputsAsZip(t *testing.T) {
+	config.Config.MaxArtifactSize = 0
+	workDir := t.TempDir()
+	for _, fileName := range []string{resultSummaryFile, resultHtmlFile, resultJunitFile, newmanLogFile} {
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, fileName), []byte(fileName), 0600))
+	}
+
+	artifacts, messages, err := getArtifacts(&PostmanState{WorkDir: workDir, Attempt: 1, ArtifactFormat: artifactFormatZip})
+
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+	require.Len(t, artifacts, 1)
+	assert.Equal(t, "$(experimentKey)_$(executionId)_postman.zip", artifacts[0].Label)
+
+	data, err := base64.StdEncoding.DecodeString(artifacts[0].Data)
+	require.NoError(t, err)
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	var names []string
+	for _, file := range archive.File {
+		names = append(names, file.Name)
+	}
+	assert.Equal(t, []string{resultSummaryFile, resultJunitFile, resultHtmlFile, newmanLogFile}, names)
+}
@@ -0,0 +1,397 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-kit/exthttp"
+	"github.com/steadybit/extension-kit/extutil"
+	"github.com/steadybit/extension-postman/v2/config"
+)
+
+const (
+	collectionsResource  = "collections"
+	environmentsResource = "environments"
+	httpResource         = "http"
+)
+
+var postmanCache *resourceCache
+
+// resourceCache keeps the last downloaded version of collections and environments on disk, so
+// runs can continue with them while the Postman API is unavailable. Each entry is keyed by the
+// resource id and remembers the updatedAt of the cached version, which lets discovery refresh
+// only the collections that changed.
+type resourceCache struct {
+	dir          string
+	maxStaleness time.Duration
+	// mutex serializes writes of the same entry by discovery and concurrent runs
+	mutex sync.Mutex
+
+	// hits counts the lookups served from the cache, as fallback or as unchanged version of an
+	// HTTP source, misses the lookups that were not: downloads that refreshed the cache and
+	// fallbacks without a usable version. refreshes counts the cached collections and
+	// environments discovery downloaded again.
+	hits      atomic.Int64
+	misses    atomic.Int64
+	refreshes atomic.Int64
+}
+
+// cacheEntry is the metadata stored next to a cached resource.
+type cacheEntry struct {
+	Id string `json:"id"`
+	// Account is the Postman account the resource was downloaded with, empty for the default account.
+	Account   string `json:"account,omitempty"`
+	Name      string `json:"name,omitempty"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+	// VerifiedAt is the last time the Postman API confirmed this version as the current one.
+	VerifiedAt time.Time `json:"verifiedAt"`
+	// Url, ETag and LastModified identify the version of a resource fetched from an HTTP source.
+	Url          string `json:"url,omitempty"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// CacheStats is the state of the cache exposed via GET /postman/cache.
+type CacheStats struct {
+	Enabled      bool   `json:"enabled"`
+	Entries      int    `json:"entries"`
+	Hits         int64  `json:"hits"`
+	Misses       int64  `json:"misses"`
+	Refreshes    int64  `json:"refreshes"`
+	MaxStaleness string `json:"maxStaleness,omitempty"`
+}
+
+// postmanApiStatusError is returned if the Postman API answered with an unexpected status code.
+type postmanApiStatusError struct {
+	resource   string
+	statusCode int
+	status     string
+}
+
+func (e *postmanApiStatusError) Error() string {
+	return fmt.Sprintf("failed to download %s from postman api, got status code %s", e.resource, e.status)
+}
+
+// isPostmanApiUnavailable tells whether the error means the API could not serve the request at
+// the moment, as opposed to the resource not existing or the API key lacking access to it.
+func isPostmanApiUnavailable(err error) bool {
+	var statusErr *postmanApiStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.statusCode == http.StatusTooManyRequests || statusErr.statusCode >= 500
+	}
+	return err != nil
+}
+
+// InitCache sets up the cache configured via CacheDir. Without a cache, runs fail as long as the
+// Postman API is unavailable.
+func InitCache() {
+	if config.Config.CacheDir == "" {
+		log.Info().Msg("Collection cache is disabled.")
+		return
+	}
+	maxStaleness, err := time.ParseDuration(config.Config.CacheMaxStaleness)
+	if err != nil {
+		log.Error().Msgf("Failed to parse cache max staleness, the collection cache is disabled: %s", err)
+		return
+	}
+	cache, err := newResourceCache(config.Config.CacheDir, maxStaleness)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to create collection cache at %s, the cache is disabled.", config.Config.CacheDir)
+		return
+	}
+	postmanCache = cache
+}
+
+func newResourceCache(dir string, maxStaleness time.Duration) (*resourceCache, error) {
+	for _, resource := range []string{collectionsResource, environmentsResource, httpResource} {
+		if err := os.MkdirAll(filepath.Join(dir, resource), 0700); err != nil {
+			return nil, err
+		}
+	}
+	return &resourceCache{dir: dir, maxStaleness: maxStaleness}, nil
+}
+
+func (c *resourceCache) dataPath(resource, id string) string {
+	return filepath.Join(c.dir, resource, filepath.Base(id)+".json")
+}
+
+func (c *resourceCache) entryPath(resource, id string) string {
+	return filepath.Join(c.dir, resource, filepath.Base(id)+".meta.json")
+}
+
+// entry returns the metadata of the cached resource or nil if it is not cached.
+func (c *resourceCache) entry(resource, id string) *cacheEntry {
+	content, err := os.ReadFile(c.entryPath(resource, id))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		log.Warn().Msgf("Ignoring corrupt cache entry for %s %s: %s", resource, id, err)
+		return nil
+	}
+	return &entry
+}
+
+// store caches the resource at path, downloaded with the account, as the current version.
+func (c *resourceCache) store(account, resource, id, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	entry := cacheEntry{Id: id}
+	if account != config.DefaultAccount {
+		entry.Account = account
+	}
+	entry.Name, entry.UpdatedAt = readResourceVersion(resource, content)
+	return c.storeContent(resource, content, entry)
+}
+
+// storeContent caches the content with the given metadata as the current version.
+func (c *resourceCache) storeContent(resource string, content []byte, entry cacheEntry) error {
+	entry.VerifiedAt = time.Now()
+	metadata, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	id := entry.Id
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := writeFileAtomically(c.dataPath(resource, id), content); err != nil {
+		return err
+	}
+	return writeFileAtomically(c.entryPath(resource, id), metadata)
+}
+
+// verify marks the cached version as still current.
+func (c *resourceCache) verify(resource string, entry cacheEntry) error {
+	entry.VerifiedAt = time.Now()
+	metadata, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return writeFileAtomically(c.entryPath(resource, entry.Id), metadata)
+}
+
+// restore copies the cached resource to destPath if it was verified within the max staleness.
+func (c *resourceCache) restore(resource, id, destPath string) (*cacheEntry, error) {
+	entry, content, err := c.load(resource, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(destPath, content, 0600); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// load returns the cached resource, optionally only if it was verified within the max staleness.
+func (c *resourceCache) load(resource, id string, checkStaleness bool) (*cacheEntry, []byte, error) {
+	entry := c.entry(resource, id)
+	if entry == nil {
+		return nil, nil, fmt.Errorf("%s %s is not cached", resource, id)
+	}
+	if age := time.Since(entry.VerifiedAt); checkStaleness && c.maxStaleness > 0 && age > c.maxStaleness {
+		return nil, nil, fmt.Errorf("cached %s %s is %s old, exceeding the maximum staleness of %s", resource, id, age.Round(time.Second), c.maxStaleness)
+	}
+	c.mutex.Lock()
+	content, err := os.ReadFile(c.dataPath(resource, id))
+	c.mutex.Unlock()
+	if err != nil {
+		return nil, nil, err
+	}
+	return entry, content, nil
+}
+
+// findByName returns the ids of the cached resources of the account with the given name.
+func (c *resourceCache) findByName(account, resource, name string) []string {
+	if account == config.DefaultAccount {
+		account = ""
+	}
+	paths, err := filepath.Glob(filepath.Join(c.dir, resource, "*.meta.json"))
+	if err != nil {
+		return nil
+	}
+	var ids []string
+	for _, path := range paths {
+		var entry cacheEntry
+		content, err := os.ReadFile(path)
+		if err != nil || json.Unmarshal(content, &entry) != nil {
+			continue
+		}
+		if entry.Account == account && entry.Name == name {
+			ids = append(ids, entry.Id)
+		}
+	}
+	return ids
+}
+
+func (c *resourceCache) stats() CacheStats {
+	stats := CacheStats{
+		Enabled:      true,
+		Hits:         c.hits.Load(),
+		Misses:       c.misses.Load(),
+		Refreshes:    c.refreshes.Load(),
+		MaxStaleness: c.maxStaleness.String(),
+	}
+	for _, resource := range []string{collectionsResource, environmentsResource, httpResource} {
+		paths, _ := filepath.Glob(filepath.Join(c.dir, resource, "*.meta.json"))
+		stats.Entries += len(paths)
+	}
+	return stats
+}
+
+// readResourceVersion reads name and updatedAt of a collection (from its info block) or an
+// environment.
+func readResourceVersion(resource string, content []byte) (string, string) {
+	var version struct {
+		Name      string `json:"name"`
+		UpdatedAt string `json:"updatedAt"`
+		Info      *struct {
+			Name      string `json:"name"`
+			UpdatedAt string `json:"updatedAt"`
+		} `json:"info"`
+	}
+	if err := json.Unmarshal(content, &version); err != nil {
+		return "", ""
+	}
+	if resource == collectionsResource && version.Info != nil {
+		return version.Info.Name, version.Info.UpdatedAt
+	}
+	return version.Name, version.UpdatedAt
+}
+
+func writeFileAtomically(path string, content []byte) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := temp.Write(content); err != nil {
+		_ = temp.Close()
+		_ = os.Remove(temp.Name())
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		_ = os.Remove(temp.Name())
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
+
+// fetchPostmanResource downloads the resource to destPath and caches it. If the Postman API is
+// unavailable, the cached version is used instead as long as it is not too stale; the returned
+// message then warns that the run uses a cached version. Aborted downloads are not replaced by the
+// cached version.
+func fetchPostmanResource(ctx context.Context, account postmanAccount, resource, id, wrapperKey, destPath string) (*action_kit_api.Message, error) {
+	err := downloadPostmanResource(ctx, account, resource, id, wrapperKey, destPath)
+	if postmanCache == nil {
+		return nil, err
+	}
+	if err == nil {
+		postmanCache.misses.Add(1)
+		if err := postmanCache.store(account.name, resource, id, destPath); err != nil {
+			log.Warn().Msgf("Failed to cache %s %s: %s", resource, id, err)
+		}
+		return nil, nil
+	}
+	if ctx.Err() != nil || !isPostmanApiUnavailable(err) {
+		return nil, err
+	}
+
+	entry, cacheErr := postmanCache.restore(resource, id, destPath)
+	if cacheErr != nil {
+		postmanCache.misses.Add(1)
+		return nil, fmt.Errorf("%w (no cached version available: %s)", err, cacheErr)
+	}
+	postmanCache.hits.Add(1)
+	message := fmt.Sprintf("Postman API is unavailable (%s), using the cached version of %s %s last verified at %s.", err, resource, id, entry.VerifiedAt.Format(time.RFC3339))
+	if entry.UpdatedAt != "" {
+		message = fmt.Sprintf("Postman API is unavailable (%s), using the cached version of %s %s updated at %s, last verified at %s.", err, resource, id, entry.UpdatedAt, entry.VerifiedAt.Format(time.RFC3339))
+	}
+	log.Warn().Msg(message)
+	return &action_kit_api.Message{
+		Level:   extutil.Ptr(action_kit_api.Warn),
+		Message: message,
+	}, nil
+}
+
+// refreshCachedCollections is called by discovery with the current collections. Cached
+// collections whose updatedAt changed are downloaded again, the others are marked as verified.
+func refreshCachedCollections(account postmanAccount, collections []PostmanCollection) {
+	for _, collection := range collections {
+		refreshCachedVersion(account, collectionsResource, collection.Id, collection.UpdatedAt, "collection")
+	}
+}
+
+// refreshCachedEnvironments is called by discovery with the current environments, like
+// refreshCachedCollections.
+func refreshCachedEnvironments(account postmanAccount, environments []PostmanEnvironment) {
+	for _, environment := range environments {
+		refreshCachedVersion(account, environmentsResource, environment.Id, environment.UpdatedAt, "environment")
+	}
+}
+
+// refreshCachedVersion downloads the cached resource again if its updatedAt changed and marks it
+// as verified otherwise. Resources that are not cached are skipped.
+func refreshCachedVersion(account postmanAccount, resource, id, updatedAt, wrapperKey string) {
+	if postmanCache == nil {
+		return
+	}
+	entry := postmanCache.entry(resource, id)
+	if entry == nil {
+		return
+	}
+	if entry.UpdatedAt != "" && entry.UpdatedAt == updatedAt {
+		if err := postmanCache.verify(resource, *entry); err != nil {
+			log.Warn().Msgf("Failed to update cache entry of %s %s: %s", wrapperKey, id, err)
+		}
+		return
+	}
+	if err := refreshCachedResource(account, resource, id, wrapperKey); err != nil {
+		log.Warn().Msgf("Failed to refresh cached %s %s: %s", wrapperKey, id, err)
+		return
+	}
+	postmanCache.refreshes.Add(1)
+}
+
+func refreshCachedResource(account postmanAccount, resource, id, wrapperKey string) error {
+	temp, err := os.CreateTemp("", "steadybit-postman-refresh-*.json")
+	if err != nil {
+		return err
+	}
+	_ = temp.Close()
+	defer func() { _ = os.Remove(temp.Name()) }()
+	if err := downloadPostmanResource(context.Background(), account, resource, id, wrapperKey, temp.Name()); err != nil {
+		return err
+	}
+	return postmanCache.store(account.name, resource, id, temp.Name())
+}
+
+// RegisterCacheHandlers exposes the cache state via GET /postman/cache.
+func RegisterCacheHandlers() {
+	exthttp.RegisterHttpHandler("GET /postman/cache", getCacheStats)
+}
+
+func getCacheStats(w http.ResponseWriter, _ *http.Request, _ []byte) {
+	if postmanCache == nil {
+		exthttp.WriteBody(w, CacheStats{})
+		return
+	}
+	exthttp.WriteBody(w, postmanCache.stats())
+}
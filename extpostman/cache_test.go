@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-postman/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFlakyPostmanApiStub serves the collection in version updatedAt.Load() with the status code
+// statusCode.Load() to the returned account.
+func newFlakyPostmanApiStub(t *testing.T, statusCode *atomic.Int32, updatedAt *atomic.Value) postmanAccount {
+	t.Helper()
+	useFastPostmanApiRetries(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if code := int(statusCode.Load()); code != http.StatusOK {
+			w.WriteHeader(code)
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, "/environments/") {
+			_, _ = w.Write([]byte(`{"environment":{"name":"staging","updatedAt":"` + updatedAt.Load().(string) + `","values":[]}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"collection":{"info":{"name":"test","updatedAt":"` + updatedAt.Load().(string) + `"},"item":[]}}`))
+	}))
+	t.Cleanup(server.Close)
+	return postmanAccount{name: config.DefaultAccount, baseUrl: server.URL}
+}
+
+func useTestCache(t *testing.T, maxStaleness time.Duration) *resourceCache {
+	t.Helper()
+	cache, err := newResourceCache(t.TempDir(), maxStaleness)
+	require.NoError(t, err)
+	postmanCache = cache
+	t.Cleanup(func() { postmanCache = nil })
+	return cache
+}
+
+func TestFetchPostmanResourceFallsBackToCache(t *testing.T) {
+	var statusCode atomic.Int32
+	var updatedAt atomic.Value
+	statusCode.Store(http.StatusOK)
+	updatedAt.Store("2026-01-01T00:00:00.000Z")
+	account := newFlakyPostmanApiStub(t, &statusCode, &updatedAt)
+	cache := useTestCache(t, time.Hour)
+	destPath := filepath.Join(t.TempDir(), "collection.json")
+
+	message, err := DownloadCollection(context.Background(), account, "c1", destPath)
+	require.NoError(t, err)
+	assert.Nil(t, message)
+	assert.Equal(t, "2026-01-01T00:00:00.000Z", cache.entry(collectionsResource, "c1").UpdatedAt)
+
+	// the API is unavailable, the cached version is used
+	statusCode.Store(http.StatusServiceUnavailable)
+	require.NoError(t, os.Remove(destPath))
+	message, err = DownloadCollection(context.Background(), account, "c1", destPath)
+	require.NoError(t, err)
+	require.NotNil(t, message)
+	assert.Equal(t, action_kit_api.Warn, *message.Level)
+	assert.Contains(t, message.Message, "using the cached version of collections c1 updated at 2026-01-01T00:00:00.000Z")
+	assert.FileExists(t, destPath)
+
+	// not cached
+	_, err = DownloadCollection(context.Background(), account, "c2", destPath)
+	assert.ErrorContains(t, err, "no cached version available")
+
+	// a missing collection is not served from the cache
+	statusCode.Store(http.StatusNotFound)
+	_, err = DownloadCollection(context.Background(), account, "c1", destPath)
+	assert.ErrorContains(t, err, "404")
+
+	// the first download and the fallback without cached version are misses
+	stats := cache.stats()
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(2), stats.Misses)
+	assert.Equal(t, 1, stats.Entries)
+}
+
+func TestFetchPostmanResourceRejectsStaleCache(t *testing.T) {
+	var statusCode atomic.Int32
+	var updatedAt atomic.Value
+	statusCode.Store(http.StatusOK)
+	updatedAt.Store("2026-01-01T00:00:00.000Z")
+	account := newFlakyPostmanApiStub(t, &statusCode, &updatedAt)
+	cache := useTestCache(t, time.Minute)
+	destPath := filepath.Join(t.TempDir(), "collection.json")
+
+	_, err := DownloadCollection(context.Background(), account, "c1", destPath)
+	require.NoError(t, err)
+	entry := cache.entry(collectionsResource, "c1")
+	entry.VerifiedAt = time.Now().Add(-time.Hour)
+	require.NoError(t, writeFileAtomically(cache.entryPath(collectionsResource, "c1"), []byte(`{"id":"c1","verifiedAt":"`+entry.VerifiedAt.Format(time.RFC3339)+`"}`)))
+
+	statusCode.Store(http.StatusTooManyRequests)
+	_, err = DownloadCollection(context.Background(), account, "c1", destPath)
+	assert.ErrorContains(t, err, "exceeding the maximum staleness of 1m0s")
+}
+
+func TestRefreshCachedCollections(t *testing.T) {
+	var statusCode atomic.Int32
+	var updatedAt atomic.Value
+	statusCode.Store(http.StatusOK)
+	updatedAt.Store("2026-01-01T00:00:00.000Z")
+	account := newFlakyPostmanApiStub(t, &statusCode, &updatedAt)
+	cache := useTestCache(t, time.Hour)
+	_, err := DownloadCollection(context.Background(), account, "c1", filepath.Join(t.TempDir(), "collection.json"))
+	require.NoError(t, err)
+
+	// unchanged collections are not downloaded again
+	refreshCachedCollections(account, []PostmanCollection{{Id: "c1", UpdatedAt: "2026-01-01T00:00:00.000Z"}, {Id: "c2"}})
+	assert.Equal(t, int64(0), cache.refreshes.Load())
+
+	updatedAt.Store("2026-02-01T00:00:00.000Z")
+	refreshCachedCollections(account, []PostmanCollection{{Id: "c1", UpdatedAt: "2026-02-01T00:00:00.000Z"}})
+	assert.Equal(t, int64(1), cache.refreshes.Load())
+	assert.Equal(t, "2026-02-01T00:00:00.000Z", cache.entry(collectionsResource, "c1").UpdatedAt)
+	assert.Nil(t, cache.entry(collectionsResource, "c2"))
+}
+
+func TestRefreshCachedEnvironments(t *testing.T) {
+	var statusCode atomic.Int32
+	var updatedAt atomic.Value
+	statusCode.Store(http.StatusOK)
+	updatedAt.Store("2026-01-01T00:00:00.000Z")
+	account := newFlakyPostmanApiStub(t, &statusCode, &updatedAt)
+	cache := useTestCache(t, time.Hour)
+	_, err := DownloadEnvironment(context.Background(), account, "e1", filepath.Join(t.TempDir(), "environment.json"))
+	require.NoError(t, err)
+
+	refreshCachedEnvironments(account, []PostmanEnvironment{{Id: "e1", UpdatedAt: "2026-01-01T00:00:00.000Z"}})
+	assert.Equal(t, int64(0), cache.refreshes.Load())
+
+	updatedAt.Store("2026-02-01T00:00:00.000Z")
+	refreshCachedEnvironments(account, []PostmanEnvironment{{Id: "e1", UpdatedAt: "2026-02-01T00:00:00.000Z"}})
+	assert.Equal(t, int64(1), cache.refreshes.Load())
+	assert.Equal(t, "2026-02-01T00:00:00.000Z", cache.entry(environmentsResource, "e1").UpdatedAt)
+}
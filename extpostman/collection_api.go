@@ -1,57 +1,56 @@
 package extpostman
 
 import (
-	"encoding/json"
-	"io"
+	"context"
 	"net/http"
+	"net/url"
 
-	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
 )
 
 type PostmanCollectionResult struct {
 	Collections []PostmanCollection `json:"collections"`
 }
 type PostmanCollection struct {
-	Id   string `json:"id"`
-	Name string `json:"name"`
+	Id        string                 `json:"id"`
+	Uid       string                 `json:"uid"`
+	Name      string                 `json:"name"`
+	Owner     string                 `json:"owner"`
+	CreatedAt string                 `json:"createdAt"`
+	UpdatedAt string                 `json:"updatedAt"`
+	IsPublic  bool                   `json:"isPublic"`
+	Fork      *PostmanCollectionFork `json:"fork,omitempty"`
 }
 
-// DownloadCollection fetches the collection from the Postman API and writes it to destPath.
-func DownloadCollection(collectionId, destPath string) error {
-	return downloadPostmanResource("collections", collectionId, "collection", destPath)
+// PostmanCollectionFork is set if the collection is a fork of another collection.
+type PostmanCollectionFork struct {
+	Label     string `json:"label"`
+	CreatedAt string `json:"createdAt"`
+	From      string `json:"from"`
 }
 
-func GetPostmanCollections() []PostmanCollection {
-	req, err := newPostmanApiRequest("collections")
-	if err != nil {
-		log.Error().Msgf("Failed to create request for postman api. Got error: %s", err)
-		return nil
-	}
-
-	response, err := postmanHttpClient.Do(req)
-	if err != nil {
-		log.Error().Msgf("Failed to get collections from postman api. Got error: %s", err)
-		return nil
-	}
-	if response.StatusCode != http.StatusOK {
-		log.Error().Msgf("Failed to get collections from postman api. Got status code: %s", response.Status)
-		return nil
-	}
+// DownloadCollection fetches the collection from the Postman API and writes it to destPath. If
+// the API is unavailable, a cached version may be used, which the returned message warns about.
+func DownloadCollection(ctx context.Context, account postmanAccount, collectionId, destPath string) (*action_kit_api.Message, error) {
+	return fetchPostmanResource(ctx, account, collectionsResource, collectionId, "collection", destPath)
+}
 
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			log.Error().Msgf("Failed to close response body. Got error: %s", err)
-			return
-		}
-	}(response.Body)
+// GetPostmanCollections lists all collections the account can access. Failures are returned as
+// *postmanListError.
+func GetPostmanCollections(account postmanAccount) ([]PostmanCollection, error) {
+	return getPostmanCollections(account, "")
+}
 
+// getPostmanCollections lists the collections of the workspace, or all collections the API key
+// can access if workspaceId is empty.
+func getPostmanCollections(account postmanAccount, workspaceId string) ([]PostmanCollection, error) {
+	var query url.Values
+	if workspaceId != "" {
+		query = url.Values{"workspace": {workspaceId}}
+	}
 	var result PostmanCollectionResult
-	err = json.NewDecoder(response.Body).Decode(&result)
-	if err != nil {
-		log.Error().Msgf("Failed to decode response body. Got error: %s", err)
-		return nil
+	if err := callPostmanApi(postmanHttpClient, context.Background(), account, http.MethodGet, query, &result, collectionsResource); err != nil {
+		return nil, &postmanListError{resource: collectionsResource, workspaceId: workspaceId, err: err}
 	}
-
-	return result.Collections
+	return result.Collections, nil
 }
@@ -0,0 +1,324 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+)
+
+// maxConcurrentCollectionSummaries bounds the collections of the Postman API downloaded at once
+// to summarize them.
+const maxConcurrentCollectionSummaries = 4
+
+const (
+	attributeCollectionRequestCount   = "postman.collection.request-count"
+	attributeCollectionAssertionCount = "postman.collection.assertion-count"
+	attributeCollectionFolder         = "postman.collection.folder"
+	attributeCollectionHasTests       = "postman.collection.has-tests"
+	attributeCollectionHost           = "postman.collection.host"
+	attributeCollectionUrlPathPrefix  = "postman.collection.url-path-prefix"
+)
+
+var (
+	// e.g. pm.test("Status code is 200", ...) or the legacy tests["Status code is 200"] = ...
+	assertionPattern = regexp.MustCompile(`\bpm\.test\s*\(|\btests\s*\[`)
+
+	// apiCollectionSummaries remembers the summaries of the collections of the Postman API by id,
+	// so discovery downloads a collection only once its updatedAt changed.
+	apiCollectionSummaries     = make(map[string]apiCollectionSummary)
+	apiCollectionSummariesLock sync.Mutex
+)
+
+// collectionSummary is derived from the content of a collection and tells whether it can act
+// as a meaningful check.
+type collectionSummary struct {
+	requests   int
+	assertions int
+	folders    []string
+	// hosts and pathPrefixes (the first path segment) of the request urls, as far as their
+	// variables could be resolved with the collection variables
+	hosts        []string
+	pathPrefixes []string
+
+	variables map[string]string
+}
+
+type apiCollectionSummary struct {
+	updatedAt string
+	summary   collectionSummary
+}
+
+// summarizeCollection counts the enabled requests and the assertions of the test scripts of
+// the collection and collects the names of its folders and the hosts its requests are sent to.
+func summarizeCollection(content []byte) (collectionSummary, error) {
+	var collection map[string]any
+	if err := json.Unmarshal(content, &collection); err != nil {
+		return collectionSummary{}, err
+	}
+	summary := collectionSummary{variables: make(map[string]string)}
+	variables, _ := collection["variable"].([]any)
+	for _, variable := range variables {
+		object, _ := variable.(map[string]any)
+		key, _ := object["key"].(string)
+		if disabled, _ := object["disabled"].(bool); key == "" || disabled {
+			continue
+		}
+		if value, ok := object["value"].(string); ok {
+			summary.variables[key] = value
+		}
+	}
+	summary.addScripts(collection["event"])
+	if items, ok := collection["item"].([]any); ok {
+		summary.addItems(items)
+	}
+	return summary, nil
+}
+
+func (s *collectionSummary) addItems(items []any) {
+	for _, value := range items {
+		item, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		if disabled, _ := item["disabled"].(bool); disabled {
+			continue
+		}
+		s.addScripts(item["event"])
+		if children, ok := item["item"].([]any); ok {
+			if name, _ := item["name"].(string); name != "" {
+				s.folders = append(s.folders, name)
+			}
+			s.addItems(children)
+			continue
+		}
+		if item["request"] != nil {
+			s.requests++
+			s.addRequestUrl(item["request"])
+		}
+	}
+}
+
+func (s *collectionSummary) addScripts(value any) {
+	events, _ := value.([]any)
+	for _, event := range events {
+		object, _ := event.(map[string]any)
+		if listen, _ := object["listen"].(string); listen != "test" {
+			continue
+		}
+		script, _ := object["script"].(map[string]any)
+		switch exec := script["exec"].(type) {
+		case string:
+			s.assertions += len(assertionPattern.FindAllString(exec, -1))
+		case []any:
+			for _, line := range exec {
+				if text, ok := line.(string); ok && !strings.HasPrefix(strings.TrimSpace(text), "//") {
+					s.assertions += len(assertionPattern.FindAllString(text, -1))
+				}
+			}
+		}
+	}
+}
+
+func (s *collectionSummary) addRequestUrl(request any) {
+	var rawUrl string
+	switch typed := request.(type) {
+	case string:
+		rawUrl = typed
+	case map[string]any:
+		switch requestUrl := typed["url"].(type) {
+		case string:
+			rawUrl = requestUrl
+		case map[string]any:
+			rawUrl = rawRequestUrl(requestUrl)
+		}
+	}
+
+	// keep what can be told before the first unresolved variable, e.g. the host of
+	// https://shop.example.com/{{path}}
+	resolved, _, truncated := strings.Cut(s.resolveVariables(rawUrl), "{{")
+	if resolved == "" {
+		return
+	}
+	if !strings.Contains(resolved, "://") {
+		resolved = "http://" + resolved
+	}
+	parsed, err := url.Parse(resolved)
+	// without a path after the host, an unresolved variable may be part of the host
+	if err != nil || parsed.Hostname() == "" || (truncated && parsed.Path == "") {
+		return
+	}
+	if host := strings.ToLower(parsed.Hostname()); !slices.Contains(s.hosts, host) {
+		s.hosts = append(s.hosts, host)
+	}
+	segment, _, followed := strings.Cut(strings.TrimPrefix(parsed.Path, "/"), "/")
+	if segment == "" || (truncated && !followed && parsed.RawQuery == "") {
+		return
+	}
+	if prefix := "/" + segment; !slices.Contains(s.pathPrefixes, prefix) {
+		s.pathPrefixes = append(s.pathPrefixes, prefix)
+	}
+}
+
+// rawRequestUrl returns the raw url of a url object, or builds it from its host and path.
+func rawRequestUrl(requestUrl map[string]any) string {
+	if raw, _ := requestUrl["raw"].(string); raw != "" {
+		return raw
+	}
+	var host string
+	switch typed := requestUrl["host"].(type) {
+	case string:
+		host = typed
+	case []any:
+		var parts []string
+		for _, part := range typed {
+			if text, ok := part.(string); ok {
+				parts = append(parts, text)
+			}
+		}
+		host = strings.Join(parts, ".")
+	}
+	var path string
+	switch typed := requestUrl["path"].(type) {
+	case string:
+		path = typed
+	case []any:
+		var segments []string
+		for _, segment := range typed {
+			if text, ok := segment.(string); ok {
+				segments = append(segments, text)
+			}
+		}
+		path = strings.Join(segments, "/")
+	}
+	if path != "" && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if protocol, _ := requestUrl["protocol"].(string); protocol != "" && host != "" {
+		return protocol + "://" + host + path
+	}
+	return host + path
+}
+
+// resolveVariables replaces the references to collection variables, including references in
+// their values.
+func (s *collectionSummary) resolveVariables(value string) string {
+	for range 5 {
+		resolved := variableReferencePattern.ReplaceAllStringFunc(value, func(reference string) string {
+			name := strings.TrimSpace(reference[2 : len(reference)-2])
+			if variable, ok := s.variables[name]; ok {
+				return variable
+			}
+			return reference
+		})
+		if resolved == value {
+			break
+		}
+		value = resolved
+	}
+	return value
+}
+
+func (s collectionSummary) addAttributes(target *discovery_kit_api.Target) {
+	target.Attributes[attributeCollectionRequestCount] = []string{strconv.Itoa(s.requests)}
+	target.Attributes[attributeCollectionAssertionCount] = []string{strconv.Itoa(s.assertions)}
+	target.Attributes[attributeCollectionHasTests] = []string{strconv.FormatBool(s.assertions > 0)}
+	if len(s.folders) > 0 {
+		target.Attributes[attributeCollectionFolder] = s.folders
+	}
+	if len(s.hosts) > 0 {
+		target.Attributes[attributeCollectionHost] = s.hosts
+	}
+	if len(s.pathPrefixes) > 0 {
+		target.Attributes[attributeCollectionUrlPathPrefix] = s.pathPrefixes
+	}
+}
+
+// addCollectionSummaryAttributes adds the attributes derived from the content of the collection
+// to the target. Content that cannot be summarized adds no attributes.
+func addCollectionSummaryAttributes(target *discovery_kit_api.Target, content []byte) {
+	if summary, err := summarizeCollection(content); err == nil {
+		summary.addAttributes(target)
+	}
+}
+
+// addApiCollectionSummaries adds the attributes derived from the content of the collections of
+// the Postman API to their targets. Collections that cannot be summarized add no attributes.
+func addApiCollectionSummaries(account postmanAccount, collections []PostmanCollection, targets []*discovery_kit_api.Target) {
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, maxConcurrentCollectionSummaries)
+	for i, collection := range collections {
+		wg.Go(func() {
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			summary, err := getApiCollectionSummary(account, collection)
+			if err != nil {
+				log.Warn().Msgf("Failed to summarize collection %s: %s", collection.Id, err)
+				return
+			}
+			summary.addAttributes(targets[i])
+		})
+	}
+	wg.Wait()
+}
+
+// getApiCollectionSummary returns the summary of the collection of the Postman API. Collections
+// are downloaded again only if their updatedAt changed, preferring the cached version, which
+// outlives restarts of the extension.
+func getApiCollectionSummary(account postmanAccount, collection PostmanCollection) (collectionSummary, error) {
+	apiCollectionSummariesLock.Lock()
+	known, ok := apiCollectionSummaries[collection.Id]
+	apiCollectionSummariesLock.Unlock()
+	if ok && collection.UpdatedAt != "" && known.updatedAt == collection.UpdatedAt {
+		return known.summary, nil
+	}
+
+	content, err := readApiCollection(account, collection)
+	if err != nil {
+		return collectionSummary{}, err
+	}
+	summary, err := summarizeCollection(content)
+	if err != nil {
+		return collectionSummary{}, err
+	}
+	apiCollectionSummariesLock.Lock()
+	apiCollectionSummaries[collection.Id] = apiCollectionSummary{updatedAt: collection.UpdatedAt, summary: summary}
+	apiCollectionSummariesLock.Unlock()
+	return summary, nil
+}
+
+func readApiCollection(account postmanAccount, collection PostmanCollection) ([]byte, error) {
+	if postmanCache != nil {
+		if entry := postmanCache.entry(collectionsResource, collection.Id); entry != nil && entry.UpdatedAt != "" && entry.UpdatedAt == collection.UpdatedAt {
+			if _, content, err := postmanCache.load(collectionsResource, collection.Id, false); err == nil {
+				return content, nil
+			}
+		}
+	}
+	temp, err := os.CreateTemp("", "steadybit-postman-summary-*.json")
+	if err != nil {
+		return nil, err
+	}
+	_ = temp.Close()
+	defer func() { _ = os.Remove(temp.Name()) }()
+	if err := downloadPostmanResource(context.Background(), account, collectionsResource, collection.Id, "collection", temp.Name()); err != nil {
+		return nil, err
+	}
+	if postmanCache != nil {
+		if err := postmanCache.store(account.name, collectionsResource, collection.Id, temp.Name()); err != nil {
+			log.Warn().Msgf("Failed to cache collection %s: %s", collection.Id, err)
+		}
+	}
+	return os.ReadFile(temp.Name())
+}
@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+	"github.com/steadybit/extension-postman/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSummarizedCollection = `{"info":{"name":"shop"},
+"event":[{"listen":"test","script":{"exec":["pm.test(\"Response time is fine\", function () {","    pm.expect(pm.response.responseTime).to.be.below(500);","});"]}}],
+"item":[
+ {"name":"Orders","item":[
+  {"name":"List","request":{"method":"GET","url":"https://shop.example.com/orders"},
+   "event":[{"listen":"test","script":{"exec":["pm.test(\"ok\", () => pm.response.to.have.status(200));","// pm.test(\"disabled\", () => {});","tests[\"has body\"] = responseBody.length > 0;"]}}]},
+  {"name":"Disabled","disabled":true,"request":{"method":"GET","url":"https://shop.example.com/disabled"}},
+  {"name":"Admin","item":[{"name":"Stats","request":{"method":"GET","url":"https://shop.example.com/stats"},
+   "event":[{"listen":"prerequest","script":{"exec":["pm.test(\"not a test script\", () => {});"]}}]}]}]},
+ {"name":"Health","request":{"method":"GET","url":"https://shop.example.com/health"}}]}`
+
+func TestSummarizeCollection(t *testing.T) {
+	summary, err := summarizeCollection([]byte(testSummarizedCollection))
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, summary.requests)
+	assert.Equal(t, 3, summary.assertions)
+	assert.Equal(t, []string{"Orders", "Admin"}, summary.folders)
+	assert.Equal(t, []string{"shop.example.com"}, summary.hosts)
+	assert.Equal(t, []string{"/orders", "/stats", "/health"}, summary.pathPrefixes)
+}
+
+func TestSummarizeCollectionResolvesCollectionVariables(t *testing.T) {
+	summary, err := summarizeCollection([]byte(`{"info":{"name":"checkout"},
+"variable":[{"key":"baseUrl","value":"https://{{host}}/api"},{"key":"host","value":"Checkout.Shop.svc:8080"},{"key":"disabled","value":"other.svc","disabled":true}],
+"item":[
+ {"name":"Cart","request":{"method":"GET","url":{"raw":"{{baseUrl}}/cart","host":["{{baseUrl}}"],"path":["cart"]}}},
+ {"name":"Payment","request":{"method":"POST","url":{"protocol":"http","host":["payment","shop","svc"],"path":["v1","pay"]}}},
+ {"name":"Partial","request":{"method":"GET","url":"https://status.shop.svc/{{version}}/health"}},
+ {"name":"Unresolved host","request":{"method":"GET","url":"https://api.{{domain}}/orders"}},
+ {"name":"Environment","request":{"method":"GET","url":"{{environmentUrl}}/orders"}},
+ {"name":"Disabled variable","request":{"method":"GET","url":"https://{{disabled}}/orders"}}]}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"checkout.shop.svc", "payment.shop.svc", "status.shop.svc"}, summary.hosts)
+	assert.Equal(t, []string{"/api", "/v1"}, summary.pathPrefixes)
+}
+
+func TestApiCollectionTargetHasMetadataAndSummary(t *testing.T) {
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads.Add(1)
+		_, _ = w.Write([]byte(`{"collection":` + testSummarizedCollection + `}`))
+	}))
+	t.Cleanup(server.Close)
+	account := postmanAccount{name: config.DefaultAccount, baseUrl: server.URL}
+	collection := PostmanCollection{
+		Id:        "c-summary",
+		Uid:       "1-c-summary",
+		Name:      "shop",
+		Owner:     "1",
+		CreatedAt: "2026-01-01T00:00:00.000Z",
+		UpdatedAt: "2026-02-01T00:00:00.000Z",
+		Fork:      &PostmanCollectionFork{Label: "my fork", From: "1-c-origin"},
+	}
+
+	target := newApiCollectionTarget(account, collection)
+	addApiCollectionSummaries(account, []PostmanCollection{collection}, []*discovery_kit_api.Target{&target})
+
+	assert.Equal(t, map[string][]string{
+		"postman.collection.id":              {"c-summary"},
+		"postman.collection.name":            {"shop"},
+		"postman.collection.source":          {"api"},
+		"postman.account":                    {"default"},
+		"postman.collection.uid":             {"1-c-summary"},
+		"postman.collection.owner":           {"1"},
+		"postman.collection.created-at":      {"2026-01-01T00:00:00.000Z"},
+		"postman.collection.updated-at":      {"2026-02-01T00:00:00.000Z"},
+		"postman.collection.is-public":       {"false"},
+		"postman.collection.is-fork":         {"true"},
+		"postman.collection.fork.label":      {"my fork"},
+		"postman.collection.fork.from":       {"1-c-origin"},
+		"postman.collection.request-count":   {"3"},
+		"postman.collection.assertion-count": {"3"},
+		"postman.collection.has-tests":       {"true"},
+		"postman.collection.folder":          {"Orders", "Admin"},
+		"postman.collection.host":            {"shop.example.com"},
+		"postman.collection.url-path-prefix": {"/orders", "/stats", "/health"},
+	}, target.Attributes)
+
+	// unchanged collections are not downloaded again
+	_, err := getApiCollectionSummary(account, collection)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), downloads.Load())
+	collection.UpdatedAt = "2026-03-01T00:00:00.000Z"
+	_, err = getApiCollectionSummary(account, collection)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), downloads.Load())
+}
+
+func TestApiCollectionSummariesAreCachedAcrossRestarts(t *testing.T) {
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads.Add(1)
+		_, _ = w.Write([]byte(`{"collection":{"info":{"name":"shop","updatedAt":"2026-02-01T00:00:00.000Z"},"item":[{"name":"Health","request":"https://shop.example.com/health"}]}}`))
+	}))
+	t.Cleanup(server.Close)
+	useTestCache(t, time.Hour)
+	account := postmanAccount{name: config.DefaultAccount, baseUrl: server.URL}
+	collection := PostmanCollection{Id: "c-restart", Name: "shop", UpdatedAt: "2026-02-01T00:00:00.000Z"}
+
+	_, err := getApiCollectionSummary(account, collection)
+	require.NoError(t, err)
+	// the summaries in memory are gone after a restart, the cache is not
+	apiCollectionSummariesLock.Lock()
+	delete(apiCollectionSummaries, collection.Id)
+	apiCollectionSummariesLock.Unlock()
+	summary, err := getApiCollectionSummary(account, collection)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.requests)
+	assert.Equal(t, int32(1), downloads.Load())
+}
+
+func TestApiCollectionSummariesDownloadABoundedNumberOfCollectionsAtOnce(t *testing.T) {
+	var mutex sync.Mutex
+	var active, maxActive int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		active++
+		maxActive = max(maxActive, active)
+		mutex.Unlock()
+		defer func() {
+			mutex.Lock()
+			active--
+			mutex.Unlock()
+		}()
+		time.Sleep(10 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"collection":{"info":{"name":"shop"},"item":[]}}`))
+	}))
+	t.Cleanup(server.Close)
+	account := postmanAccount{name: config.DefaultAccount, baseUrl: server.URL}
+	var collections []PostmanCollection
+	var targets []*discovery_kit_api.Target
+	for i := range 3 * maxConcurrentCollectionSummaries {
+		collection := PostmanCollection{Id: fmt.Sprintf("c-bounded-%d", i), UpdatedAt: "2026-02-01T00:00:00.000Z"}
+		collections = append(collections, collection)
+		targets = append(targets, new(newApiCollectionTarget(account, collection)))
+	}
+
+	addApiCollectionSummaries(account, collections, targets)
+
+	assert.LessOrEqual(t, maxActive, maxConcurrentCollectionSummaries)
+	for _, target := range targets {
+		assert.Equal(t, []string{"0"}, target.Attributes["postman.collection.request-count"])
+	}
+}
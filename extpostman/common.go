@@ -4,6 +4,7 @@
 package extpostman
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -15,7 +16,6 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"github.com/steadybit/extension-kit/extbuild"
-	"github.com/steadybit/extension-postman/v2/config"
 )
 
 const (
@@ -27,38 +27,92 @@ const (
 // request so a slow or unresponsive Postman API cannot hang the action/discovery indefinitely.
 var postmanHttpClient = &http.Client{Timeout: 30 * time.Second}
 
-// newPostmanApiRequest builds an authenticated GET request against the Postman API. The API
-// key is sent via the X-API-Key header (never as a query parameter), so it is not exposed on
-// a child process command line or persisted in the serialized action state.
-func newPostmanApiRequest(pathSegments ...string) (*http.Request, error) {
-	specification := config.Config
+// newPostmanApiRequest builds an authenticated GET request against the Postman API of the account.
+func newPostmanApiRequest(ctx context.Context, account postmanAccount, pathSegments ...string) (*http.Request, error) {
+	return newPostmanApiMethodRequest(ctx, account, http.MethodGet, nil, pathSegments...)
+}
 
-	resourceUrl, err := url.Parse(specification.PostmanBaseUrl)
+// newPostmanApiMethodRequest builds an authenticated request with the given method and body
+// against the Postman API of the account. The API key is sent via the X-API-Key header (never as
+// a query parameter), so it is not exposed on a child process command line or persisted in the
+// serialized action state.
+func newPostmanApiMethodRequest(ctx context.Context, account postmanAccount, method string, body io.Reader, pathSegments ...string) (*http.Request, error) {
+	resourceUrl, err := url.Parse(account.baseUrl)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse postman base url: %w", err)
 	}
 	resourceUrl.Path += "/" + strings.Join(pathSegments, "/")
 
-	req, err := http.NewRequest(http.MethodGet, resourceUrl.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, method, resourceUrl.String(), body)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add("X-API-Key", specification.PostmanApiKey)
+	if body != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	req.Header.Add("X-API-Key", account.apiKey)
 	req.Header.Add("Accept", "*/*")
 	req.Header.Add("User-Agent", fmt.Sprintf("steadybit-extension-postman/%s", extbuild.GetSemverVersionStringOrUnknown()))
 	return req, nil
 }
 
+// postmanListError is returned if the collections or environments of the Postman API could not
+// be listed. It wraps the error of the request, e.g. a *postmanApiStatusError.
+type postmanListError struct {
+	resource    string
+	workspaceId string
+	err         error
+}
+
+func (e *postmanListError) Error() string {
+	if e.workspaceId != "" {
+		return fmt.Sprintf("failed to list %s of workspace %s: %s", e.resource, e.workspaceId, e.err)
+	}
+	return fmt.Sprintf("failed to list %s: %s", e.resource, e.err)
+}
+
+func (e *postmanListError) Unwrap() error {
+	return e.err
+}
+
+// callPostmanApi sends a request without body to the Postman API and decodes the response into
+// result.
+func callPostmanApi(client *http.Client, ctx context.Context, account postmanAccount, method string, query url.Values, result any, pathSegments ...string) error {
+	req, err := newPostmanApiMethodRequest(ctx, account, method, nil, pathSegments...)
+	if err != nil {
+		return fmt.Errorf("failed to create request for postman api: %w", err)
+	}
+	req.URL.RawQuery = query.Encode()
+
+	response, err := doPostmanApiRequest(client, req)
+	if err != nil {
+		return fmt.Errorf("failed to request %s from postman api: %w", pathSegments[0], err)
+	}
+	defer func(Body io.ReadCloser) {
+		if err := Body.Close(); err != nil {
+			log.Error().Msgf("Failed to close response body. Got error: %s", err)
+		}
+	}(response.Body)
+
+	if response.StatusCode != http.StatusOK {
+		return &postmanApiStatusError{resource: pathSegments[0], statusCode: response.StatusCode, status: response.Status}
+	}
+	if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response body: %w", err)
+	}
+	return nil
+}
+
 // downloadPostmanResource fetches a resource from the Postman API and writes it to destPath.
 // The API wraps the resource in a single top-level key (e.g. {"collection": {...}}); when
 // present, that inner object is unwrapped so newman receives the canonical file format.
-func downloadPostmanResource(resourcePath, id, wrapperKey, destPath string) error {
-	req, err := newPostmanApiRequest(resourcePath, id)
+func downloadPostmanResource(ctx context.Context, account postmanAccount, resourcePath, id, wrapperKey, destPath string) error {
+	req, err := newPostmanApiRequest(ctx, account, resourcePath, id)
 	if err != nil {
 		return err
 	}
 
-	response, err := postmanHttpClient.Do(req)
+	response, err := doPostmanApiRequest(postmanHttpClient, req)
 	if err != nil {
 		return fmt.Errorf("failed to request %s from postman api: %w", resourcePath, err)
 	}
@@ -69,7 +123,7 @@ func downloadPostmanResource(resourcePath, id, wrapperKey, destPath string) erro
 	}()
 
 	if response.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download %s from postman api, got status code %s", resourcePath, response.Status)
+		return &postmanApiStatusError{resource: resourcePath, statusCode: response.StatusCode, status: response.Status}
 	}
 
 	body, err := io.ReadAll(response.Body)
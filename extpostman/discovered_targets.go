@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+)
+
+// attributeDiscoveryStaleness is the age of the targets of a source whose discovery failed, e.g.
+// 3h0m0s. Targets of a successful discovery don't have it.
+const attributeDiscoveryStaleness = "postman.discovery.staleness"
+
+var (
+	lastDiscoveredCollections = newDiscoveredTargets()
+	lastDiscoveredMonitors    = newDiscoveredTargets()
+)
+
+// discoveredTargets remembers the targets each source discovered last, so the targets of a
+// source whose discovery fails, e.g. while the Postman API is unavailable, don't disappear and
+// break the experiments referencing them.
+type discoveredTargets struct {
+	mutex    sync.Mutex
+	bySource map[string]discoveryResult
+}
+
+type discoveryResult struct {
+	targets      []discovery_kit_api.Target
+	discoveredAt time.Time
+}
+
+func newDiscoveredTargets() *discoveredTargets {
+	return &discoveredTargets{bySource: make(map[string]discoveryResult)}
+}
+
+// update remembers the targets of the source if its discovery succeeded. If it failed, the
+// targets the source still discovered are returned along with the ones it discovered last and
+// is missing now, the latter with their age as postman.discovery.staleness attribute. The error
+// is only returned if the source discovered no targets at all.
+func (d *discoveredTargets) update(source string, targets []discovery_kit_api.Target, err error) ([]discovery_kit_api.Target, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if err == nil {
+		d.bySource[source] = discoveryResult{targets: targets, discoveredAt: time.Now()}
+		return targets, nil
+	}
+	last, ok := d.bySource[source]
+	if !ok {
+		if len(targets) == 0 {
+			return nil, err
+		}
+		log.Warn().Msgf("Failed to discover some targets of %s, reporting the %d discovered ones: %s", source, len(targets), err)
+		return targets, nil
+	}
+
+	discovered := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		discovered[target.Id] = true
+	}
+	staleness := time.Since(last.discoveredAt).Round(time.Second)
+	result := slices.Clone(targets)
+	for _, target := range last.targets {
+		if discovered[target.Id] {
+			continue
+		}
+		target.Attributes = maps.Clone(target.Attributes)
+		target.Attributes[attributeDiscoveryStaleness] = []string{staleness.String()}
+		result = append(result, target)
+	}
+	log.Warn().Msgf("Failed to discover targets of %s, keeping %d targets discovered %s ago: %s", source, len(result)-len(targets), staleness, err)
+	return result, nil
+}
@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+	"github.com/steadybit/extension-postman/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverAllCollectionsKeepsTargetsWhileThePostmanApiFails(t *testing.T) {
+	useFastPostmanApiRetries(t)
+	var unavailable atomic.Bool
+	unavailable.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case unavailable.Load():
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/collections":
+			_, _ = w.Write([]byte(`{"collections":[{"id":"c1","name":"checkout"}]}`))
+		case r.URL.Path == "/collections/c1":
+			_, _ = w.Write([]byte(`{"collection":{"info":{"name":"checkout"},"item":[]}}`))
+		case r.URL.Path == "/environments":
+			_, _ = w.Write([]byte(`{"environments":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	// only the Postman API is configured, whatever other tests configured before
+	previous := config.Config
+	config.Config = config.Specification{}
+	lastDiscoveredCollections = newDiscoveredTargets()
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_API_KEY", "123456")
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_BASE_URL", server.URL)
+	config.ParseConfiguration()
+	t.Cleanup(func() {
+		config.Config = previous
+		lastDiscoveredCollections = newDiscoveredTargets()
+	})
+
+	discoveryErrors := testutil.ToFloat64(metricDiscoveryErrors.WithLabelValues("collections", "api/default"))
+
+	// without previously discovered targets, the cached discovery keeps its targets
+	_, err := discoverAllCollections()
+	var listErr *postmanListError
+	require.ErrorAs(t, err, &listErr)
+	assert.True(t, isPostmanApiUnavailable(err))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metricDiscoveredTargets.WithLabelValues("collections", "api/default")))
+
+	unavailable.Store(false)
+	targets, err := discoverAllCollections()
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	assert.NotContains(t, targets[0].Attributes, "postman.discovery.staleness")
+
+	unavailable.Store(true)
+	targets, err = discoverAllCollections()
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	assert.Equal(t, "c1", targets[0].Id)
+	assert.Equal(t, []string{"0s"}, targets[0].Attributes["postman.discovery.staleness"])
+	// the kept targets are still reported
+	assert.Equal(t, float64(1), testutil.ToFloat64(metricDiscoveredTargets.WithLabelValues("collections", "api/default")))
+	assert.Equal(t, discoveryErrors+2, testutil.ToFloat64(metricDiscoveryErrors.WithLabelValues("collections", "api/default")))
+}
+
+func TestDiscoveredTargetsReportTheirStaleness(t *testing.T) {
+	discovered := newDiscoveredTargets()
+	target := discovery_kit_api.Target{Id: "c1", Attributes: map[string][]string{"postman.collection.name": {"checkout"}}}
+	_, err := discovered.update("file", []discovery_kit_api.Target{target}, nil)
+	require.NoError(t, err)
+	result := discovered.bySource["file"]
+	result.discoveredAt = time.Now().Add(-3 * time.Hour)
+	discovered.bySource["file"] = result
+
+	targets, err := discovered.update("file", nil, errors.New("directory is gone"))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3h0m0s"}, targets[0].Attributes["postman.discovery.staleness"])
+	assert.NotContains(t, target.Attributes, "postman.discovery.staleness")
+
+	_, err = discovered.update("git", nil, errors.New("clone failed"))
+	assert.EqualError(t, err, "clone failed")
+}
+
+func TestDiscoveredTargetsKeepTheTargetsMissingFromAPartiallyFailedDiscovery(t *testing.T) {
+	discovered := newDiscoveredTargets()
+	shop := discovery_kit_api.Target{Id: "http:shop", Attributes: map[string][]string{"postman.collection.name": {"shop"}}}
+	checkout := discovery_kit_api.Target{Id: "http:checkout", Attributes: map[string][]string{"postman.collection.name": {"checkout"}}}
+	_, err := discovered.update("http", []discovery_kit_api.Target{shop, checkout}, nil)
+	require.NoError(t, err)
+
+	targets, err := discovered.update("http", []discovery_kit_api.Target{shop}, errors.New("failed to download collection checkout"))
+
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+	assert.Equal(t, "http:shop", targets[0].Id)
+	assert.NotContains(t, targets[0].Attributes, "postman.discovery.staleness")
+	assert.Equal(t, "http:checkout", targets[1].Id)
+	assert.Contains(t, targets[1].Attributes, "postman.discovery.staleness")
+
+	// without previously discovered targets, the discovered ones are reported
+	targets, err = discovered.update("file", []discovery_kit_api.Target{shop}, errors.New("collection file checkout is not valid JSON"))
+	require.NoError(t, err)
+	assert.Len(t, targets, 1)
+}
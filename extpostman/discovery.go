@@ -89,22 +89,209 @@ func (d *collectionDiscovery) DescribeAttributes() []discovery_kit_api.Attribute
 				Other: "Collection Ids",
 			},
 		},
+		{
+			Attribute: "postman.collection.source",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Collection Source",
+				Other: "Collection Sources",
+			},
+		},
+		{
+			Attribute: "postman.collection.uid",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Collection Uid",
+				Other: "Collection Uids",
+			},
+		},
+		{
+			Attribute: "postman.collection.owner",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Collection Owner",
+				Other: "Collection Owners",
+			},
+		},
+		{
+			Attribute: "postman.collection.created-at",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Collection Created At",
+				Other: "Collection Created At",
+			},
+		},
+		{
+			Attribute: "postman.collection.updated-at",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Collection Updated At",
+				Other: "Collection Updated At",
+			},
+		},
+		{
+			Attribute: "postman.collection.is-public",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Collection Is Public",
+				Other: "Collection Is Public",
+			},
+		},
+		{
+			Attribute: "postman.collection.is-fork",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Collection Is Fork",
+				Other: "Collection Is Fork",
+			},
+		},
+		{
+			Attribute: "postman.collection.fork.label",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Collection Fork Label",
+				Other: "Collection Fork Labels",
+			},
+		},
+		{
+			Attribute: "postman.collection.fork.from",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Collection Forked From",
+				Other: "Collection Forked From",
+			},
+		},
+		{
+			Attribute: "postman.collection.request-count",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Collection Request Count",
+				Other: "Collection Request Counts",
+			},
+		},
+		{
+			Attribute: "postman.collection.assertion-count",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Collection Assertion Count",
+				Other: "Collection Assertion Counts",
+			},
+		},
+		{
+			Attribute: "postman.collection.folder",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Collection Folder",
+				Other: "Collection Folders",
+			},
+		},
+		{
+			Attribute: "postman.collection.has-tests",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Collection Has Tests",
+				Other: "Collection Has Tests",
+			},
+		},
+		{
+			Attribute: "postman.collection.host",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Collection Host",
+				Other: "Collection Hosts",
+			},
+		},
+		{
+			Attribute: "postman.collection.url-path-prefix",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Collection URL Path Prefix",
+				Other: "Collection URL Path Prefixes",
+			},
+		},
+		{
+			Attribute: attributeDiscoveryStaleness,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Discovery Staleness",
+				Other: "Discovery Staleness",
+			},
+		},
+		{
+			Attribute: attributeAccount,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Postman Account",
+				Other: "Postman Accounts",
+			},
+		},
+		{
+			Attribute: "postman.workspace.id",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Workspace Id",
+				Other: "Workspace Ids",
+			},
+		},
+		{
+			Attribute: "postman.workspace.name",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Workspace Name",
+				Other: "Workspace Names",
+			},
+		},
+		{
+			Attribute: "postman.workspace.type",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Workspace Type",
+				Other: "Workspace Types",
+			},
+		},
+		{
+			Attribute: "postman.environment.id",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Environment ID",
+				Other: "Environment IDs",
+			},
+		},
+		{
+			Attribute: "postman.environment.name",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Environment Name",
+				Other: "Environment Names",
+			},
+		},
+		{
+			Attribute: "postman.collection.path",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Collection Path",
+				Other: "Collection Paths",
+			},
+		},
+		{
+			Attribute: "postman.collection.repository",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Collection Repository",
+				Other: "Collection Repositories",
+			},
+		},
+		{
+			Attribute: "postman.collection.branch",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Collection Branch",
+				Other: "Collection Branches",
+			},
+		},
+		{
+			Attribute: "postman.collection.commit",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Collection Commit",
+				Other: "Collection Commits",
+			},
+		},
+		{
+			Attribute: "postman.collection.url",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Collection URL",
+				Other: "Collection URLs",
+			},
+		},
+		{
+			Attribute: "postman.collection.specification",
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Collection OpenAPI Specification",
+				Other: "Collection OpenAPI Specifications",
+			},
+		},
 	}
 }
 
 func (d *collectionDiscovery) DiscoverTargets(_ context.Context) ([]discovery_kit_api.Target, error) {
-	collections := GetPostmanCollections()
-	targets := make([]discovery_kit_api.Target, len(collections))
-	for i, collection := range collections {
-		targets[i] = discovery_kit_api.Target{
-			Id:         collection.Id,
-			TargetType: targetID,
-			Label:      collection.Name,
-			Attributes: map[string][]string{
-				"postman.collection.id":   {collection.Id},
-				"postman.collection.name": {collection.Name},
-			},
-		}
+	targets, err := discoverAllCollections()
+	if err != nil {
+		return nil, err
 	}
 	return discovery_kit_commons.ApplyAttributeExcludes(targets, []string{}), nil
 }
@@ -1,28 +1,40 @@
 package extpostman
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
+	"net/http"
+	"net/url"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+)
+
+const (
+	attributeEnvironmentId   = "postman.environment.id"
+	attributeEnvironmentName = "postman.environment.name"
 )
 
 type PostmanEnvironmentResult struct {
 	Environments []PostmanEnvironment `json:"environments"`
 }
 type PostmanEnvironment struct {
-	Id   string `json:"id"`
-	Name string `json:"name"`
+	Id        string `json:"id"`
+	Uid       string `json:"uid"`
+	Name      string `json:"name"`
+	UpdatedAt string `json:"updatedAt"`
 }
 
-// DownloadEnvironment fetches the environment from the Postman API and writes it to destPath.
-func DownloadEnvironment(environmentId, destPath string) error {
-	return downloadPostmanResource("environments", environmentId, "environment", destPath)
+// DownloadEnvironment fetches the environment from the Postman API and writes it to destPath. If
+// the API is unavailable, a cached version may be used, which the returned message warns about.
+func DownloadEnvironment(ctx context.Context, account postmanAccount, environmentId, destPath string) (*action_kit_api.Message, error) {
+	return fetchPostmanResource(ctx, account, environmentsResource, environmentId, "environment", destPath)
 }
 
-func GetPostEnvironmentId(environmentIdOrName string) (string, error) {
+// GetPostEnvironmentId resolves the environment id. Names are looked up among the environments
+// of the given workspaces, or among all accessible environments if no workspace is given.
+func GetPostEnvironmentId(ctx context.Context, account postmanAccount, environmentIdOrName string, workspaceIds ...string) (string, error) {
 	log.Info().Msgf("Searching for environment with id or name '%s'", environmentIdOrName)
 	environmentId, err := uuid.Parse(environmentIdOrName)
 	if err == nil {
@@ -30,7 +42,14 @@ func GetPostEnvironmentId(environmentIdOrName string) (string, error) {
 		return environmentId.String(), nil
 	}
 
-	environments := GetPostmanEnvironments()
+	environments, err := getWorkspaceEnvironments(ctx, account, workspaceIds)
+	if err != nil {
+		log.Error().Msgf("Failed to get Environments from postman api. Got error: %s", err)
+		if postmanCache != nil && ctx.Err() == nil && isPostmanApiUnavailable(err) {
+			return getCachedEnvironmentId(account, environmentIdOrName)
+		}
+		return "", fmt.Errorf("failed to get environments: %w", err)
+	}
 	log.Info().Msgf("Found %d environments", len(environments))
 	var uniqueEnvironmentId string
 	counter := 0
@@ -51,33 +70,58 @@ func GetPostEnvironmentId(environmentIdOrName string) (string, error) {
 	return "", fmt.Errorf("failed to find environment with name '%s'", environmentIdOrName)
 }
 
-func GetPostmanEnvironments() []PostmanEnvironment {
-	req, err := newPostmanApiRequest("environments")
-	if err != nil {
-		log.Error().Msgf("Failed to create request for postman api. Got error: %s", err)
-		return nil
+// getCachedEnvironmentId looks up the environment by name among the cached environments of the account.
+func getCachedEnvironmentId(account postmanAccount, environmentName string) (string, error) {
+	ids := postmanCache.findByName(account.name, environmentsResource, environmentName)
+	if len(ids) > 1 {
+		return "", fmt.Errorf("found multiple cached environments with name '%s'", environmentName)
 	}
-
-	response, err := postmanHttpClient.Do(req)
-	if err != nil {
-		log.Error().Msgf("Failed to get Environments from postman api. Got error: %s", err)
-		return nil
+	if len(ids) == 0 {
+		return "", fmt.Errorf("postman api is unavailable and no environment with name '%s' is cached", environmentName)
 	}
+	log.Info().Msgf("Found cached environment with name '%s' and id '%s'", environmentName, ids[0])
+	return ids[0], nil
+}
 
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			log.Error().Msgf("Failed to close response body. Got error: %s", err)
-			return
-		}
-	}(response.Body)
+// GetPostmanEnvironments lists all environments the account can access. Failures are returned as
+// *postmanListError.
+func GetPostmanEnvironments(account postmanAccount) ([]PostmanEnvironment, error) {
+	return getPostmanEnvironments(context.Background(), account, "")
+}
 
+// getPostmanEnvironments lists the environments of the workspace, or all environments the API
+// key can access if workspaceId is empty.
+func getPostmanEnvironments(ctx context.Context, account postmanAccount, workspaceId string) ([]PostmanEnvironment, error) {
+	var query url.Values
+	if workspaceId != "" {
+		query = url.Values{"workspace": {workspaceId}}
+	}
 	var result PostmanEnvironmentResult
-	err = json.NewDecoder(response.Body).Decode(&result)
-	if err != nil {
-		log.Error().Msgf("Failed to decode response body. Got error: %s", err)
-		return nil
+	if err := callPostmanApi(postmanHttpClient, ctx, account, http.MethodGet, query, &result, environmentsResource); err != nil {
+		return nil, &postmanListError{resource: environmentsResource, workspaceId: workspaceId, err: err}
 	}
+	return result.Environments, nil
+}
 
-	return result.Environments
+// getWorkspaceEnvironments lists the environments of the workspaces, or all environments the API
+// key can access if no workspace is given.
+func getWorkspaceEnvironments(ctx context.Context, account postmanAccount, workspaceIds []string) ([]PostmanEnvironment, error) {
+	if len(workspaceIds) == 0 {
+		return getPostmanEnvironments(ctx, account, "")
+	}
+	var environments []PostmanEnvironment
+	seen := make(map[string]bool)
+	for _, workspaceId := range workspaceIds {
+		workspaceEnvironments, err := getPostmanEnvironments(ctx, account, workspaceId)
+		if err != nil {
+			return nil, err
+		}
+		for _, environment := range workspaceEnvironments {
+			if !seen[environment.Id] {
+				seen[environment.Id] = true
+				environments = append(environments, environment)
+			}
+		}
+	}
+	return environments, nil
 }
@@ -0,0 +1,283 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+// getEnvironments returns the environments to run the collection in, without duplicates. More
+// than one environment makes the run an environment matrix.
+func getEnvironments(request PostmanConfig) []string {
+	var environments []string
+	for _, environment := range append([]string{request.EnvironmentIdOrName}, request.EnvironmentIdsOrNames...) {
+		environment = strings.TrimSpace(environment)
+		if environment != "" && !slices.Contains(environments, environment) {
+			environments = append(environments, environment)
+		}
+	}
+	return environments
+}
+
+// prepareEnvironmentMatrix prepares one run per environment. Each run has its own working
+// directory below the one of the state, so the environments, reports and secrets of the runs
+// stay apart. The runs are labelled with the name of their environment.
+func prepareEnvironmentMatrix(ctx context.Context, state *PostmanState, source collectionSource, attributes map[string][]string, request PostmanConfig, collectionFile string, environments []string) ([]action_kit_api.Message, error) {
+	var messages []action_kit_api.Message
+	state.EnvironmentIdOrName = strings.Join(environments, ", ")
+	state.Matrix = make([]PostmanState, 0, len(environments))
+	for i, environmentIdOrName := range environments {
+		run := PostmanState{
+			RunId:               fmt.Sprintf("%s-%d", state.RunId, i+1),
+			CollectionId:        state.CollectionId,
+			CollectionName:      state.CollectionName,
+			EnvironmentIdOrName: environmentIdOrName,
+			WorkDir:             filepath.Join(state.WorkDir, fmt.Sprintf("environment-%d", i+1)),
+		}
+		if err := os.Mkdir(run.WorkDir, 0700); err != nil {
+			return nil, extension_kit.ToError("Failed to create working directory.", err)
+		}
+		runMessages, err := prepareRun(ctx, &run, source, attributes, request, collectionFile)
+		if err != nil {
+			return nil, extension_kit.ToError(fmt.Sprintf("Failed to prepare the run in environment %s.", environmentIdOrName), err)
+		}
+
+		run.Environment = environmentIdOrName
+		if environment, err := readJsonObject(filepath.Join(run.WorkDir, environmentFile)); err == nil {
+			if name, _ := environment["name"].(string); name != "" {
+				run.Environment = name
+			}
+		}
+		if slices.ContainsFunc(state.Matrix, func(other PostmanState) bool { return other.Environment == run.Environment }) {
+			run.Environment = fmt.Sprintf("%s-%d", run.Environment, i+1)
+		}
+		messages = append(messages, labelMessages(run.Environment, runMessages)...)
+		state.Matrix = append(state.Matrix, run)
+	}
+	log.Info().Msgf("Prepared run of collection %s in %d environments", state.CollectionId, len(state.Matrix))
+	return messages, nil
+}
+
+func startEnvironmentMatrix(state *PostmanState) (*action_kit_api.StartResult, error) {
+	run := &state.Matrix[0]
+	if err := startMatrixRun(run); err != nil {
+		return nil, new(extension_kit.ToError(fmt.Sprintf("Failed to start the run in environment %s.", run.Environment), err))
+	}
+	metricActiveRuns.Inc()
+	state.StartedAt = run.StartedAt
+
+	names := make([]string, 0, len(state.Matrix))
+	for _, run := range state.Matrix {
+		names = append(names, run.Environment)
+	}
+	return &action_kit_api.StartResult{
+		Messages: new([]action_kit_api.Message{{
+			Level:   extutil.Ptr(action_kit_api.Info),
+			Message: fmt.Sprintf("Running the collection in %d environments one after another: %s", len(names), strings.Join(names, ", ")),
+		}}),
+	}, nil
+}
+
+func startMatrixRun(run *PostmanState) error {
+	log.Info().Msgf("Starting newman in environment %s", run.Environment)
+	if err := startNewman(run, run.Command); err != nil {
+		return err
+	}
+	run.StartedAt = new(time.Now())
+	// the command is only needed again if the collection is re-run on failure
+	if run.MaxAttempts <= 1 {
+		run.Command = nil
+	}
+	return nil
+}
+
+// environmentMatrixStatus checks the run of the current environment. Once it finished, the run
+// of the next environment is started; after the last one the verdicts of all runs are aggregated.
+func environmentMatrixStatus(state *PostmanState) (*action_kit_api.StatusResult, error) {
+	run := &state.Matrix[state.MatrixIndex]
+	result, err := runStatus(run)
+	if err != nil {
+		return nil, err
+	}
+	var messages []action_kit_api.Message
+	if result.Messages != nil {
+		messages = *result.Messages
+	}
+	if !result.Completed {
+		return &action_kit_api.StatusResult{Completed: false, Messages: new(labelMessages(run.Environment, messages))}, nil
+	}
+
+	run.Verdict, run.VerdictTitle = getMatrixRunVerdict(result.Error)
+	message := action_kit_api.Message{
+		Level:   extutil.Ptr(action_kit_api.Info),
+		Message: fmt.Sprintf("Run in environment %s succeeded", run.Environment),
+	}
+	if run.Verdict != runVerdictSuccess {
+		message.Level = extutil.Ptr(action_kit_api.Error)
+		message.Message = fmt.Sprintf("Run in environment %s %s: %s", run.Environment, run.Verdict, run.VerdictTitle)
+	}
+	messages = labelMessages(run.Environment, append(messages, message))
+
+	if state.MatrixIndex+1 < len(state.Matrix) {
+		state.MatrixIndex++
+		next := &state.Matrix[state.MatrixIndex]
+		if err := startMatrixRun(next); err != nil {
+			return nil, new(extension_kit.ToError(fmt.Sprintf("Failed to start the run in environment %s.", next.Environment), err))
+		}
+		return &action_kit_api.StatusResult{Completed: false, Messages: new(messages)}, nil
+	}
+
+	verdict, summary := aggregateEnvironmentMatrix(state.Matrix)
+	return &action_kit_api.StatusResult{
+		Completed: true,
+		Error:     verdict,
+		Messages:  new(append(messages, summary)),
+	}, nil
+}
+
+// getMatrixRunVerdict maps the error of a finished run to its verdict and the title describing it.
+func getMatrixRunVerdict(err *action_kit_api.ActionKitError) (string, string) {
+	switch {
+	case err == nil:
+		return runVerdictSuccess, ""
+	case err.Status != nil && *err.Status == action_kit_api.Failed:
+		return runVerdictFailed, err.Title
+	default:
+		return runVerdictErrored, err.Title
+	}
+}
+
+// aggregateEnvironmentMatrix fails the step if the run failed in any environment. It errors if
+// runs only errored, e.g. because newman could not reach a host.
+func aggregateEnvironmentMatrix(runs []PostmanState) (*action_kit_api.ActionKitError, action_kit_api.Message) {
+	var failed, errored []string
+	for _, run := range runs {
+		switch run.Verdict {
+		case runVerdictFailed:
+			failed = append(failed, fmt.Sprintf("%s (%s)", run.Environment, run.VerdictTitle))
+		case runVerdictErrored:
+			errored = append(errored, fmt.Sprintf("%s (%s)", run.Environment, run.VerdictTitle))
+		}
+	}
+	unsuccessful := append(failed, errored...)
+	message := action_kit_api.Message{
+		Level:   extutil.Ptr(action_kit_api.Info),
+		Message: fmt.Sprintf("Collection run succeeded in %d of %d environments", len(runs)-len(unsuccessful), len(runs)),
+	}
+	if len(unsuccessful) == 0 {
+		return nil, message
+	}
+
+	status := action_kit_api.Errored
+	if len(failed) > 0 {
+		status = action_kit_api.Failed
+	}
+	message.Level = extutil.Ptr(action_kit_api.Error)
+	return &action_kit_api.ActionKitError{
+		Status: extutil.Ptr(status),
+		Title:  fmt.Sprintf("Failed in %d of %d environments: %s", len(unsuccessful), len(runs), strings.Join(unsuccessful, ", ")),
+	}, message
+}
+
+// stopEnvironmentMatrix stops the runs that were started and collects the outputs of all of them.
+// The step is recorded as one run holding the runs per environment.
+func stopEnvironmentMatrix(state *PostmanState) (*action_kit_api.StopResult, error) {
+	if state.Matrix[0].CmdStateID == "" {
+		// newman was never started
+		return nil, nil
+	}
+	metricActiveRuns.Dec()
+	artifacts := make([]action_kit_api.Artifact, 0)
+	var messages []action_kit_api.Message
+	var summaries []RunSummary
+	defer func() {
+		recordRun(summarizeEnvironmentMatrix(state, summaries, time.Now()))
+	}()
+	for i := range state.Matrix {
+		run := &state.Matrix[i]
+		if run.CmdStateID == "" {
+			continue
+		}
+		summary, runArtifacts, runMessages, err := stopRun(run)
+		if summary != nil {
+			summary.Environment = run.Environment
+			summaries = append(summaries, *summary)
+		}
+		if err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, runArtifacts...)
+		messages = append(messages, labelMessages(run.Environment, runMessages)...)
+	}
+	return &action_kit_api.StopResult{
+		Artifacts: new(artifacts),
+		Messages:  new(messages),
+	}, nil
+}
+
+// summarizeEnvironmentMatrix summarizes the step from the runs in the environments. The step
+// failed if a run failed, errored if runs only errored, and was stopped if a run was stopped or
+// never started.
+func summarizeEnvironmentMatrix(state *PostmanState, runs []RunSummary, endedAt time.Time) RunSummary {
+	summary := RunSummary{
+		Id:             state.RunId,
+		CollectionId:   state.CollectionId,
+		CollectionName: state.CollectionName,
+		Environment:    state.EnvironmentIdOrName,
+		EndedAt:        endedAt,
+		Environments:   runs,
+	}
+	if state.StartedAt != nil {
+		summary.StartedAt = *state.StartedAt
+	}
+	verdicts := make(map[string]bool)
+	for _, run := range runs {
+		verdicts[run.Verdict] = true
+		summary.Attempts += run.Attempts
+		if summary.ExitCode == 0 {
+			summary.ExitCode = run.ExitCode
+		}
+		for _, failure := range run.TopFailures {
+			if len(summary.TopFailures) < maxTopFailures {
+				summary.TopFailures = append(summary.TopFailures, fmt.Sprintf("%s: %s", run.Environment, failure))
+			}
+		}
+	}
+	switch {
+	case verdicts[runVerdictFailed]:
+		summary.Verdict = runVerdictFailed
+	case verdicts[runVerdictErrored]:
+		summary.Verdict = runVerdictErrored
+	case verdicts[runVerdictStopped] || len(runs) < len(state.Matrix):
+		summary.Verdict = runVerdictStopped
+	default:
+		summary.Verdict = runVerdictSuccess
+	}
+	return summary
+}
+
+// labelMessages adds the environment to the fields of the messages.
+func labelMessages(environment string, messages []action_kit_api.Message) []action_kit_api.Message {
+	for i := range messages {
+		fields := action_kit_api.MessageFields{}
+		if messages[i].Fields != nil {
+			maps.Copy(fields, *messages[i].Fields)
+		}
+		fields["environment"] = environment
+		messages[i].Fields = &fields
+	}
+	return messages
+}
@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-kit/extutil"
+	"github.com/steadybit/extension-postman/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEnvironments(t *testing.T) {
+	assert.Empty(t, getEnvironments(PostmanConfig{}))
+	assert.Equal(t, []string{"staging-eu"}, getEnvironments(PostmanConfig{EnvironmentIdOrName: "staging-eu"}))
+	assert.Equal(t, []string{"staging-eu", "staging-us"}, getEnvironments(PostmanConfig{
+		EnvironmentIdOrName:   "staging-eu",
+		EnvironmentIdsOrNames: []string{" staging-us ", "staging-eu", ""},
+	}))
+}
+
+func TestPrepareEnvironmentMatrix(t *testing.T) {
+	server := newPostmanApiStub(t)
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_API_KEY", "123456")
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_BASE_URL", server.URL)
+	config.ParseConfiguration()
+
+	requestBody := extutil.JsonMangle(action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{
+			"duration":              60000,
+			"environmentIdOrName":   "5f757f0d-de24-462c-867f-256bb696d2dd",
+			"environmentIdsOrNames": []string{"7a1c3e2b-0d4f-4b8e-9c6a-2f5e8d1b3a7c"},
+			"secretEnvironment":     []map[string]string{{"key": "token", "value": "s3cr3t"}},
+		},
+		Target: &action_kit_api.Target{
+			Attributes: map[string][]string{
+				"postman.collection.id": {"645797"},
+			},
+		},
+	})
+	action := NewPostmanAction()
+	state := action.NewEmptyState()
+
+	_, err := action.Prepare(context.TODO(), &state, requestBody)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(state.WorkDir) })
+
+	assert.Nil(t, state.Command)
+	require.Len(t, state.Matrix, 2)
+	for i, run := range state.Matrix {
+		assert.Equal(t, state.WorkDir, filepath.Dir(run.WorkDir))
+		assert.Equal(t, filepath.Join(state.WorkDir, "collection.json"), run.Command[2])
+		assert.Contains(t, run.Command, filepath.Join(run.WorkDir, environmentFile))
+		assert.FileExists(t, filepath.Join(run.WorkDir, environmentFile))
+		assert.Equal(t, []string{"s3cr3t"}, mustReadSecretEnvironmentValues(t, run.WorkDir))
+		assert.Equal(t, fmt.Sprintf("%s-%d", state.RunId, i+1), run.RunId)
+	}
+	// both environments of the stub are named "env"
+	assert.Equal(t, "env", state.Matrix[0].Environment)
+	assert.Equal(t, "env-2", state.Matrix[1].Environment)
+}
+
+func mustReadSecretEnvironmentValues(t *testing.T, workDir string) []string {
+	t.Helper()
+	values, err := readSecretEnvironmentValues(filepath.Join(workDir, environmentFile))
+	require.NoError(t, err)
+	return values
+}
+
+func TestEnvironmentMatrixRunsEnvironmentsOneAfterAnother(t *testing.T) {
+	store, err := openRunHistoryStore(filepath.Join(t.TempDir(), "runs.db"), 10)
+	require.NoError(t, err)
+	runHistory = store
+	t.Cleanup(func() {
+		runHistory = nil
+		_ = store.close()
+	})
+	failedRuns := testutil.ToFloat64(metricRuns.WithLabelValues(runVerdictFailed))
+	workDir := t.TempDir()
+	newRun := func(environment string, command ...string) PostmanState {
+		runDir := filepath.Join(workDir, environment)
+		require.NoError(t, os.Mkdir(runDir, 0700))
+		return PostmanState{RunId: "4715-" + environment, Command: command, WorkDir: runDir, MaxAttempts: 1, Environment: environment}
+	}
+	state := PostmanState{
+		RunId:   "4715",
+		WorkDir: workDir,
+		Matrix: []PostmanState{
+			newRun("staging-eu", "sh", "-c", "echo eu"),
+			newRun("staging-us", "sh", "-c", "echo us; exit 1"),
+		},
+	}
+	// the failing run left a report with a failed assertion
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "staging-us", resultSummaryFile), []byte(`{"Run":{"Stats":{"Assertions":{"total":2,"failed":1}}}}`), 0600))
+	action := PostmanAction{}
+
+	_, err = action.Start(context.TODO(), &state)
+	require.NoError(t, err)
+
+	var messages []action_kit_api.Message
+	var result *action_kit_api.StatusResult
+	require.Eventually(t, func() bool {
+		result, err = action.Status(context.TODO(), &state)
+		require.NoError(t, err)
+		messages = append(messages, *result.Messages...)
+		return result.Completed
+	}, 10*time.Second, 50*time.Millisecond)
+
+	assert.Equal(t, 1, state.MatrixIndex)
+	assert.Equal(t, runVerdictSuccess, state.Matrix[0].Verdict)
+	assert.Equal(t, runVerdictFailed, state.Matrix[1].Verdict)
+	require.NotNil(t, result.Error)
+	assert.Equal(t, action_kit_api.Failed, *result.Error.Status)
+	assert.Equal(t, "Failed in 1 of 2 environments: staging-us (1 assertions failed)", result.Error.Title)
+
+	environments := map[string]string{}
+	for _, message := range messages {
+		if message.Fields != nil {
+			environments[message.Message] = (*message.Fields)["environment"]
+		}
+	}
+	assert.Equal(t, "staging-eu", environments["eu\n"])
+	assert.Equal(t, "staging-us", environments["us\n"])
+
+	stopResult, err := action.Stop(context.TODO(), &state)
+	require.NoError(t, err)
+	var labels []string
+	for _, artifact := range *stopResult.Artifacts {
+		labels = append(labels, artifact.Label)
+	}
+	assert.Equal(t, []string{"$(experimentKey)_$(executionId)_postman_staging-eu.log", "$(experimentKey)_$(executionId)_postman_staging-us.json", "$(experimentKey)_$(executionId)_postman_staging-us.log"}, labels)
+	assert.NoDirExists(t, workDir)
+
+	// the step is recorded and counted once, with the runs per environment
+	assert.Equal(t, failedRuns+1, testutil.ToFloat64(metricRuns.WithLabelValues(runVerdictFailed)))
+	summary, err := store.get("4715")
+	require.NoError(t, err)
+	require.NotNil(t, summary)
+	assert.Equal(t, runVerdictFailed, summary.Verdict)
+	require.Len(t, summary.Environments, 2)
+	assert.Equal(t, "staging-eu", summary.Environments[0].Environment)
+	assert.Equal(t, runVerdictSuccess, summary.Environments[0].Verdict)
+	assert.Equal(t, runVerdictFailed, summary.Environments[1].Verdict)
+	runs, err := store.list()
+	require.NoError(t, err)
+	assert.Len(t, runs, 1)
+}
@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+)
+
+const (
+	collectionFileSuffix  = ".postman_collection.json"
+	environmentFileSuffix = ".postman_environment.json"
+)
+
+// fileSource provides the collections and environments exported to a directory, e.g. mounted
+// from a ConfigMap, for installations without access to the Postman API.
+type fileSource struct {
+	dir string
+}
+
+// collectionFileInfo is the part of an exported collection identifying it.
+type collectionFileInfo struct {
+	Info struct {
+		PostmanId string `json:"_postman_id"`
+		Name      string `json:"name"`
+	} `json:"info"`
+}
+
+func (s fileSource) name() string {
+	return sourceFile
+}
+
+func (s fileSource) discoverCollections() ([]discovery_kit_api.Target, error) {
+	paths, err := findFiles(s.dir, collectionFileSuffix)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]discovery_kit_api.Target, 0, len(paths))
+	var errs []error
+	for _, path := range paths {
+		content, err := os.ReadFile(filepath.Join(s.dir, path))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read collection file %s: %w", path, err))
+			continue
+		}
+		var collection collectionFileInfo
+		if err := json.Unmarshal(content, &collection); err != nil {
+			errs = append(errs, fmt.Errorf("collection file %s is not valid JSON: %w", path, err))
+			continue
+		}
+		target := newCollectionFileTarget(sourceFile, path, collection)
+		addCollectionSummaryAttributes(&target, content)
+		targets = append(targets, target)
+	}
+	return targets, errors.Join(errs...)
+}
+
+// newCollectionFileTarget creates the target of an exported collection file of the source.
+func newCollectionFileTarget(source, path string, collection collectionFileInfo) discovery_kit_api.Target {
+	id := collection.Info.PostmanId
+	if id == "" {
+		id = path
+	}
+	name := collection.Info.Name
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), collectionFileSuffix)
+	}
+	return discovery_kit_api.Target{
+		Id:         source + ":" + path,
+		TargetType: targetID,
+		Label:      name,
+		Attributes: map[string][]string{
+			attributeCollectionId:     {id},
+			attributeCollectionName:   {name},
+			attributeCollectionSource: {source},
+			attributeCollectionPath:   {path},
+		},
+	}
+}
+
+func (s fileSource) fetchCollection(_ context.Context, attributes map[string][]string, destPath string) ([]action_kit_api.Message, error) {
+	path, err := singleAttribute(attributes, attributeCollectionPath)
+	if err != nil {
+		return nil, err
+	}
+	return nil, copyFile(resolveInDir(s.dir, path), destPath)
+}
+
+// fetchEnvironment looks up the environment file by the id or name of the environment.
+func (s fileSource) fetchEnvironment(_ context.Context, _ map[string][]string, environmentIdOrName, destPath string) ([]action_kit_api.Message, error) {
+	path, err := findEnvironmentFile(s.dir, environmentIdOrName)
+	if err != nil {
+		return nil, err
+	}
+	return nil, copyFile(filepath.Join(s.dir, path), destPath)
+}
+
+// findEnvironmentFile returns the path of the environment file with the given id or, if no id
+// matches, with the given unique name.
+func findEnvironmentFile(dir, environmentIdOrName string) (string, error) {
+	paths, err := findFiles(dir, environmentFileSuffix)
+	if err != nil {
+		return "", err
+	}
+	candidates := make(map[string][]byte, len(paths))
+	for _, path := range paths {
+		content, err := os.ReadFile(filepath.Join(dir, path))
+		if err != nil {
+			return "", err
+		}
+		candidates[path] = content
+	}
+	path, err := matchEnvironment(candidates, environmentIdOrName)
+	if err != nil {
+		return "", fmt.Errorf("%w in %s", err, dir)
+	}
+	return path, nil
+}
+
+// matchEnvironment returns the path of the environment file with the given id or, if no id
+// matches, with the given unique name among the candidates (path to content).
+func matchEnvironment(candidates map[string][]byte, environmentIdOrName string) (string, error) {
+	var byName []string
+	for _, path := range slices.Sorted(maps.Keys(candidates)) {
+		var environment postmanEnvironmentFile
+		if err := json.Unmarshal(candidates[path], &environment); err != nil {
+			log.Warn().Msgf("Skipping environment file %s, it is not valid JSON: %s", path, err)
+			continue
+		}
+		if environment.Id != "" && environment.Id == environmentIdOrName {
+			return path, nil
+		}
+		if environment.Name == environmentIdOrName {
+			byName = append(byName, path)
+		}
+	}
+	if len(byName) > 1 {
+		return "", fmt.Errorf("found multiple environments with name '%s': %s", environmentIdOrName, strings.Join(byName, ", "))
+	}
+	if len(byName) == 0 {
+		return "", fmt.Errorf("failed to find environment with id or name '%s'", environmentIdOrName)
+	}
+	return byName[0], nil
+}
+
+// findFiles returns the paths, relative to dir, of all files in dir and its subdirectories
+// with the given suffix.
+func findFiles(dir, suffix string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// the data directories of ConfigMap volumes are hidden and linked from the top level
+		if entry.IsDir() && path != dir && strings.HasPrefix(entry.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), suffix) {
+			relative, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			paths = append(paths, relative)
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// resolveInDir joins the relative path to dir without letting it escape dir.
+func resolveInDir(dir, path string) string {
+	return filepath.Join(dir, filepath.Clean(string(filepath.Separator)+path))
+}
+
+func copyFile(source, destPath string) error {
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(destPath, content, 0600)
+}
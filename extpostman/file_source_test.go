@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-kit/extutil"
+	"github.com/steadybit/extension-postman/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCollectionFile = `{"info":{"_postman_id":"8f1e5a7c-0000-4000-8000-000000000001","name":"shop","schema":"https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},"item":[{"name":"Get","request":{"method":"GET","url":"{{baseUrl}}/health"}}]}`
+
+func newTestCollectionsDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "team", ".hidden"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shop.postman_collection.json"), []byte(testCollectionFile), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "team", "unnamed.postman_collection.json"), []byte(`{"info":{},"item":[]}`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "team", ".hidden", "hidden.postman_collection.json"), []byte(`{"info":{},"item":[]}`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.postman_collection.json"), []byte(`{`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "staging.postman_environment.json"), []byte(`{"id":"e1","name":"staging","values":[{"key":"baseUrl","value":"https://staging.example.com","enabled":true}]}`), 0600))
+	return dir
+}
+
+func TestFileSourceDiscoversCollections(t *testing.T) {
+	dir := newTestCollectionsDir(t)
+
+	targets, err := fileSource{dir: dir}.discoverCollections()
+
+	assert.ErrorContains(t, err, "collection file broken.postman_collection.json is not valid JSON")
+	require.Len(t, targets, 2)
+	assert.Equal(t, "file:shop.postman_collection.json", targets[0].Id)
+	assert.Equal(t, map[string][]string{
+		"postman.collection.id":     {"8f1e5a7c-0000-4000-8000-000000000001"},
+		"postman.collection.name":   {"shop"},
+		"postman.collection.source": {"file"},
+		"postman.collection.path":   {"shop.postman_collection.json"},
+
+		"postman.collection.request-count":   {"1"},
+		"postman.collection.assertion-count": {"0"},
+		"postman.collection.has-tests":       {"false"},
+	}, targets[0].Attributes)
+	assert.Equal(t, "unnamed", targets[1].Label)
+	assert.Equal(t, []string{filepath.Join("team", "unnamed.postman_collection.json")}, targets[1].Attributes["postman.collection.id"])
+}
+
+func TestPrepareCollectionRunFromFileSourceWithoutApiKey(t *testing.T) {
+	dir := newTestCollectionsDir(t)
+	t.Setenv("STEADYBIT_EXTENSION_COLLECTIONS_DIR", dir)
+	config.ParseConfiguration()
+	config.Config.PostmanApiKey = ""
+	t.Cleanup(func() { config.Config.CollectionsDir = "" })
+
+	requestBody := extutil.JsonMangle(action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{
+			"duration":            60000,
+			"environmentIdOrName": "staging",
+			"unresolvedVariables": unresolvedVariablesFail,
+		},
+		Target: &action_kit_api.Target{
+			Attributes: map[string][]string{
+				"postman.collection.id":     {"8f1e5a7c-0000-4000-8000-000000000001"},
+				"postman.collection.source": {"file"},
+				"postman.collection.path":   {"shop.postman_collection.json"},
+			},
+		},
+	})
+	action := NewPostmanAction()
+	state := action.NewEmptyState()
+
+	result, err := action.Prepare(context.TODO(), &state, requestBody)
+
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(state.WorkDir) })
+	assert.Nil(t, result)
+	collection, err := os.ReadFile(filepath.Join(state.WorkDir, "collection.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, testCollectionFile, string(collection))
+	assert.Contains(t, state.Command, filepath.Join(state.WorkDir, environmentFile))
+
+	// the api source is not available without an api key
+	_, err = getCollectionSource(map[string][]string{"postman.collection.id": {"1"}})
+	assert.ErrorContains(t, err, `collection source "api" is not configured`)
+}
+
+func TestResolveInDirStaysInDir(t *testing.T) {
+	assert.Equal(t, filepath.Join("/collections", "etc", "passwd"), resolveInDir("/collections", "../../etc/passwd"))
+	assert.Equal(t, filepath.Join("/collections", "team", "a.json"), resolveInDir("/collections", "team/a.json"))
+}
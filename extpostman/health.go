@@ -0,0 +1,206 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"net/http"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/extension-kit/exthealth"
+	"github.com/steadybit/extension-kit/exthttp"
+	"github.com/steadybit/extension-postman/v2/config"
+)
+
+// healthFailureThreshold is the number of consecutive checks that must fail because the Postman
+// API is unavailable before an account is unhealthy, so single transient errors don't flap the
+// readiness of the extension.
+const healthFailureThreshold = 3
+
+var postmanHealth = newHealthChecker()
+
+// HealthReport is the state of the extension served via GET /postman/health.
+type HealthReport struct {
+	Ready    bool            `json:"ready"`
+	Accounts []AccountHealth `json:"accounts"`
+	Newman   NewmanHealth    `json:"newman"`
+	Cache    CacheStats      `json:"cache"`
+}
+
+// AccountHealth is the result of the checks of the API key of an account against the Postman API.
+type AccountHealth struct {
+	Account     string     `json:"account"`
+	Healthy     bool       `json:"healthy"`
+	LastSuccess *time.Time `json:"lastSuccess,omitempty"`
+	LastError   string     `json:"lastError,omitempty"`
+	LastErrorAt *time.Time `json:"lastErrorAt,omitempty"`
+	// ConsecutiveFailures counts the failed checks since the last success.
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+}
+
+type NewmanHealth struct {
+	Available bool   `json:"available"`
+	Version   string `json:"version,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// healthChecker validates the API keys of the accounts periodically and derives the readiness
+// of the extension from the results and the availability of newman.
+type healthChecker struct {
+	mutex    sync.Mutex
+	accounts map[string]*AccountHealth
+	newman   NewmanHealth
+	ready    bool
+}
+
+func newHealthChecker() *healthChecker {
+	return &healthChecker{accounts: make(map[string]*AccountHealth), ready: true}
+}
+
+// InitHealthChecks checks newman and the API keys of all accounts at startup and the keys again
+// every PostmanHealthCheckInterval, and marks the extension as not ready while they fail. The API
+// keys are checked in the background, as the checks may retry for a while during an outage of
+// the Postman API, which must not delay serving the other sources.
+func InitHealthChecks() {
+	postmanHealth.checkNewman()
+	interval, err := time.ParseDuration(config.Config.PostmanHealthCheckInterval)
+	if err != nil || interval <= 0 {
+		log.Error().Msgf("Invalid Postman health check interval %q, the API keys are only checked at startup.", config.Config.PostmanHealthCheckInterval)
+		go postmanHealth.check()
+		return
+	}
+	go func() {
+		postmanHealth.check()
+		for range time.Tick(interval) {
+			postmanHealth.check()
+		}
+	}()
+}
+
+func (h *healthChecker) checkNewman() {
+	health := NewmanHealth{}
+	if _, err := exec.LookPath("newman"); err != nil {
+		health.Error = err.Error()
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		output, err := exec.CommandContext(ctx, "newman", "--version").Output()
+		if err != nil {
+			health.Error = err.Error()
+		} else {
+			health.Available = true
+			health.Version = strings.TrimSpace(string(output))
+		}
+	}
+	if !health.Available {
+		log.Error().Msgf("Newman is not available, collections cannot be run: %s", health.Error)
+	}
+
+	h.mutex.Lock()
+	h.newman = health
+	h.mutex.Unlock()
+	h.updateReadiness()
+}
+
+// check validates the API key of every account against the /me endpoint of the Postman API.
+// A rejected key makes the account unhealthy right away, while an unavailable API only does so
+// after healthFailureThreshold failed checks in a row.
+func (h *healthChecker) check() {
+	accounts := getPostmanAccounts()
+	for _, account := range accounts {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		err := validatePostmanAccount(ctx, account)
+		cancel()
+		h.record(account.name, err)
+	}
+
+	h.mutex.Lock()
+	for name := range h.accounts {
+		if !slices.ContainsFunc(accounts, func(account postmanAccount) bool { return account.name == name }) {
+			delete(h.accounts, name)
+		}
+	}
+	h.mutex.Unlock()
+	h.updateReadiness()
+}
+
+func (h *healthChecker) record(account string, err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	health, ok := h.accounts[account]
+	if !ok {
+		health = &AccountHealth{Account: account, Healthy: true}
+		h.accounts[account] = health
+	}
+	now := time.Now()
+	if err == nil {
+		if !health.Healthy {
+			log.Info().Msgf("Postman API key of account %s is valid again.", account)
+		}
+		health.Healthy = true
+		health.LastSuccess = &now
+		health.ConsecutiveFailures = 0
+		return
+	}
+
+	health.LastError = err.Error()
+	health.LastErrorAt = &now
+	health.ConsecutiveFailures++
+	healthy := isPostmanApiUnavailable(err) && health.ConsecutiveFailures < healthFailureThreshold
+	if health.Healthy && !healthy {
+		log.Error().Msgf("Postman API key of account %s failed validation %d times in a row: %s", account, health.ConsecutiveFailures, err)
+	} else if healthy {
+		log.Warn().Msgf("Failed to validate Postman API key of account %s (%d of %d failures tolerated): %s", account, health.ConsecutiveFailures, healthFailureThreshold-1, err)
+	}
+	health.Healthy = healthy
+}
+
+// updateReadiness marks the extension as ready if newman is available and all accounts are healthy.
+func (h *healthChecker) updateReadiness() {
+	h.mutex.Lock()
+	ready := h.newman.Available
+	for _, health := range h.accounts {
+		ready = ready && health.Healthy
+	}
+	changed := ready != h.ready
+	h.ready = ready
+	h.mutex.Unlock()
+	if changed {
+		exthealth.SetReady(ready)
+	}
+}
+
+func (h *healthChecker) report() HealthReport {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	report := HealthReport{
+		Ready:    h.ready,
+		Accounts: make([]AccountHealth, 0, len(h.accounts)),
+		Newman:   h.newman,
+		Cache:    CacheStats{},
+	}
+	for _, account := range getPostmanAccounts() {
+		if health, ok := h.accounts[account.name]; ok {
+			report.Accounts = append(report.Accounts, *health)
+		}
+	}
+	if postmanCache != nil {
+		report.Cache = postmanCache.stats()
+	}
+	return report
+}
+
+// RegisterHealthHandlers exposes the detailed health state via GET /postman/health.
+func RegisterHealthHandlers() {
+	exthttp.RegisterHttpHandler("GET /postman/health", getHealthReport)
+}
+
+func getHealthReport(w http.ResponseWriter, _ *http.Request, _ []byte) {
+	exthttp.WriteBody(w, postmanHealth.report())
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/steadybit/extension-postman/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthCheckToleratesTransientFailures(t *testing.T) {
+	useFastPostmanApiRetries(t)
+	var statusCode atomic.Int32
+	statusCode.Store(http.StatusOK)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/me" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(int(statusCode.Load()))
+		_, _ = w.Write([]byte(`{"user":{"id":1}}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_API_KEY", "123456")
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_BASE_URL", server.URL)
+	config.ParseConfiguration()
+	checker := newHealthChecker()
+	checker.newman = NewmanHealth{Available: true}
+
+	checker.check()
+	report := checker.report()
+	assert.True(t, report.Ready)
+	require.Len(t, report.Accounts, 1)
+	assert.Equal(t, "default", report.Accounts[0].Account)
+	assert.NotNil(t, report.Accounts[0].LastSuccess)
+
+	// the API is unavailable, but not for long enough
+	statusCode.Store(http.StatusServiceUnavailable)
+	checker.check()
+	checker.check()
+	report = checker.report()
+	assert.True(t, report.Ready)
+	assert.Equal(t, 2, report.Accounts[0].ConsecutiveFailures)
+	assert.Contains(t, report.Accounts[0].LastError, "503")
+
+	checker.check()
+	assert.False(t, checker.report().Ready)
+
+	statusCode.Store(http.StatusOK)
+	checker.check()
+	report = checker.report()
+	assert.True(t, report.Ready)
+	assert.Equal(t, 0, report.Accounts[0].ConsecutiveFailures)
+
+	// a rejected key is no transient failure
+	statusCode.Store(http.StatusUnauthorized)
+	checker.check()
+	report = checker.report()
+	assert.False(t, report.Ready)
+	assert.False(t, report.Accounts[0].Healthy)
+}
+
+func TestHealthRequiresNewman(t *testing.T) {
+	previous := config.Config
+	config.Config = config.Specification{}
+	t.Cleanup(func() { config.Config = previous })
+	checker := newHealthChecker()
+	checker.newman = NewmanHealth{Error: `exec: "newman": executable file not found in $PATH`}
+
+	checker.check()
+
+	report := checker.report()
+	assert.False(t, report.Ready)
+	assert.Empty(t, report.Accounts)
+	assert.False(t, report.Newman.Available)
+}
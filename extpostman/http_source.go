@@ -0,0 +1,299 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+const (
+	sourceHttp = "http"
+
+	attributeCollectionUrl = "postman.collection.url"
+)
+
+// httpSource provides the collections and environments served at configured URLs, e.g. by an
+// artifact repository or a CI pipeline. Discovery downloads them on every interval. Downloads
+// are cached like the resources of the Postman API and use conditional requests, so files that
+// did not change are not transferred again.
+type httpSource struct {
+	collectionUrls  []string
+	environmentUrls []string
+	bearerToken     string
+	username        string
+	password        string
+}
+
+// httpSourceStatusError is returned if a URL answered with an unexpected status code.
+type httpSourceStatusError struct {
+	url        string
+	statusCode int
+	status     string
+}
+
+func (e *httpSourceStatusError) Error() string {
+	return fmt.Sprintf("failed to download %s, got status code %s", e.url, e.status)
+}
+
+func (s httpSource) name() string {
+	return sourceHttp
+}
+
+// discoverCollections returns the targets of the collections that could be downloaded, along with
+// the errors of the URLs that failed, so the targets of these are kept from the last discovery.
+func (s httpSource) discoverCollections() ([]discovery_kit_api.Target, error) {
+	targets := make([]discovery_kit_api.Target, 0, len(s.collectionUrls))
+	var errs []error
+	for _, collectionUrl := range s.collectionUrls {
+		content, _, err := s.fetch(context.Background(), collectionUrl)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to download collection %s: %w", redactUrlCredentials(collectionUrl), err))
+			continue
+		}
+		var collection collectionFileInfo
+		if err := json.Unmarshal(content, &collection); err != nil {
+			errs = append(errs, fmt.Errorf("collection %s is not valid JSON: %w", redactUrlCredentials(collectionUrl), err))
+			continue
+		}
+		target := newCollectionUrlTarget(redactUrlCredentials(collectionUrl), collection)
+		addCollectionSummaryAttributes(&target, content)
+		targets = append(targets, target)
+	}
+	// keep the cached environments current, so runs can fall back to them
+	for _, environmentUrl := range s.environmentUrls {
+		if _, _, err := s.fetch(context.Background(), environmentUrl); err != nil {
+			errs = append(errs, fmt.Errorf("failed to download environment %s: %w", redactUrlCredentials(environmentUrl), err))
+		}
+	}
+	return targets, errors.Join(errs...)
+}
+
+// newCollectionUrlTarget creates the target of a collection served at the (redacted) URL.
+func newCollectionUrlTarget(collectionUrl string, collection collectionFileInfo) discovery_kit_api.Target {
+	id := collection.Info.PostmanId
+	if id == "" {
+		id = collectionUrl
+	}
+	name := collection.Info.Name
+	if name == "" {
+		name = collectionUrl
+		if parsed, err := url.Parse(collectionUrl); err == nil && path.Base(parsed.Path) != "/" && path.Base(parsed.Path) != "." {
+			name = strings.TrimSuffix(path.Base(parsed.Path), collectionFileSuffix)
+		}
+	}
+	return discovery_kit_api.Target{
+		Id:         sourceHttp + ":" + collectionUrl,
+		TargetType: targetID,
+		Label:      name,
+		Attributes: map[string][]string{
+			attributeCollectionId:     {id},
+			attributeCollectionName:   {name},
+			attributeCollectionSource: {sourceHttp},
+			attributeCollectionUrl:    {collectionUrl},
+		},
+	}
+}
+
+// fetchCollection downloads the collection of the target. Only configured URLs are fetched, the
+// target attribute merely selects one of them.
+func (s httpSource) fetchCollection(ctx context.Context, attributes map[string][]string, destPath string) ([]action_kit_api.Message, error) {
+	targetUrl, err := singleAttribute(attributes, attributeCollectionUrl)
+	if err != nil {
+		return nil, err
+	}
+	index := slices.IndexFunc(s.collectionUrls, func(collectionUrl string) bool {
+		return redactUrlCredentials(collectionUrl) == targetUrl
+	})
+	if index < 0 {
+		return nil, fmt.Errorf("collection url %s is not configured", targetUrl)
+	}
+	content, message, err := s.fetch(ctx, s.collectionUrls[index])
+	if err != nil {
+		return nil, err
+	}
+	return optionalMessage(message), os.WriteFile(destPath, content, 0600)
+}
+
+// fetchEnvironment looks up the environment by id or name among the configured environment URLs.
+// URLs that cannot be downloaded are skipped, as the run may not need their environment; their
+// errors are only returned if no environment matches.
+func (s httpSource) fetchEnvironment(ctx context.Context, _ map[string][]string, environmentIdOrName, destPath string) ([]action_kit_api.Message, error) {
+	var messages []action_kit_api.Message
+	var errs []error
+	candidates := make(map[string][]byte, len(s.environmentUrls))
+	for _, environmentUrl := range s.environmentUrls {
+		content, message, err := s.fetch(ctx, environmentUrl)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			log.Warn().Msgf("Skipping environment %s: %s", redactUrlCredentials(environmentUrl), err)
+			errs = append(errs, err)
+			continue
+		}
+		candidates[redactUrlCredentials(environmentUrl)] = content
+		messages = append(messages, optionalMessage(message)...)
+	}
+	environmentUrl, err := matchEnvironment(candidates, environmentIdOrName)
+	if err != nil {
+		return nil, errors.Join(append([]error{err}, errs...)...)
+	}
+	return messages, os.WriteFile(destPath, candidates[environmentUrl], 0600)
+}
+
+// fetch downloads the resource at the URL. If the cache holds a version of it, the request is
+// conditional and an unchanged resource is served from the cache. If the URL is unavailable,
+// the cached version is used as long as it is not too stale; the returned message then warns
+// that the run uses a cached version.
+func (s httpSource) fetch(ctx context.Context, resourceUrl string) ([]byte, *action_kit_api.Message, error) {
+	id := httpCacheId(resourceUrl)
+	var entry *cacheEntry
+	if postmanCache != nil {
+		entry = postmanCache.entry(httpResource, id)
+	}
+
+	content, response, err := s.download(ctx, resourceUrl, entry)
+	if postmanCache == nil {
+		return content, nil, err
+	}
+	if err == nil && response.StatusCode == http.StatusNotModified {
+		cached, cachedContent, err := postmanCache.load(httpResource, id, false)
+		if err != nil {
+			// the cached content vanished, download it unconditionally
+			return s.fetchUncached(ctx, resourceUrl)
+		}
+		postmanCache.hits.Add(1)
+		if err := postmanCache.verify(httpResource, *cached); err != nil {
+			log.Warn().Msgf("Failed to update cache entry of %s: %s", redactUrlCredentials(resourceUrl), err)
+		}
+		return cachedContent, nil, nil
+	}
+	if err == nil {
+		postmanCache.misses.Add(1)
+		s.store(resourceUrl, content, response)
+		return content, nil, nil
+	}
+	if ctx.Err() != nil || !isHttpSourceUnavailable(err) {
+		return nil, nil, err
+	}
+
+	cached, cachedContent, cacheErr := postmanCache.load(httpResource, id, true)
+	if cacheErr != nil {
+		postmanCache.misses.Add(1)
+		return nil, nil, fmt.Errorf("%w (no cached version available: %s)", err, cacheErr)
+	}
+	postmanCache.hits.Add(1)
+	message := fmt.Sprintf("%s is unavailable (%s), using the cached version last verified at %s.", redactUrlCredentials(resourceUrl), err, cached.VerifiedAt.Format(time.RFC3339))
+	log.Warn().Msg(message)
+	return cachedContent, &action_kit_api.Message{
+		Level:   extutil.Ptr(action_kit_api.Warn),
+		Message: message,
+	}, nil
+}
+
+func (s httpSource) fetchUncached(ctx context.Context, resourceUrl string) ([]byte, *action_kit_api.Message, error) {
+	content, response, err := s.download(ctx, resourceUrl, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	postmanCache.misses.Add(1)
+	s.store(resourceUrl, content, response)
+	return content, nil, nil
+}
+
+func (s httpSource) store(resourceUrl string, content []byte, response *http.Response) {
+	entry := cacheEntry{
+		Id:           httpCacheId(resourceUrl),
+		Url:          redactUrlCredentials(resourceUrl),
+		ETag:         response.Header.Get("ETag"),
+		LastModified: response.Header.Get("Last-Modified"),
+	}
+	entry.Name, _ = readResourceVersion(collectionsResource, content)
+	if err := postmanCache.storeContent(httpResource, content, entry); err != nil {
+		log.Warn().Msgf("Failed to cache %s: %s", entry.Url, err)
+	}
+}
+
+// download requests the resource, conditionally if the cached entry is given. The content is
+// nil if the server answered with 304 Not Modified.
+func (s httpSource) download(ctx context.Context, resourceUrl string, entry *cacheEntry) ([]byte, *http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, resourceUrl, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid url %s: %s", redactUrlCredentials(resourceUrl), urlCredentialsPattern.ReplaceAllString(err.Error(), "://"))
+	}
+	req.Header.Add("Accept", "application/json, */*")
+	req.Header.Add("User-Agent", fmt.Sprintf("steadybit-extension-postman/%s", extbuild.GetSemverVersionStringOrUnknown()))
+	if s.bearerToken != "" {
+		req.Header.Add("Authorization", "Bearer "+s.bearerToken)
+	} else if s.username != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+	if entry != nil {
+		if entry.ETag != "" {
+			req.Header.Add("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Add("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	response, err := postmanHttpClient.Do(req)
+	if err != nil {
+		// the error of the client contains the url including its credentials
+		return nil, nil, fmt.Errorf("failed to request %s: %s", redactUrlCredentials(resourceUrl), urlCredentialsPattern.ReplaceAllString(err.Error(), "://"))
+	}
+	defer func() {
+		if cerr := response.Body.Close(); cerr != nil {
+			log.Error().Msgf("Failed to close response body. Got error: %s", cerr)
+		}
+	}()
+
+	if response.StatusCode == http.StatusNotModified && entry != nil {
+		return nil, response, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, nil, &httpSourceStatusError{url: redactUrlCredentials(resourceUrl), statusCode: response.StatusCode, status: response.Status}
+	}
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response body of %s: %w", redactUrlCredentials(resourceUrl), err)
+	}
+	return content, response, nil
+}
+
+// isHttpSourceUnavailable tells whether the error means the URL could not be served at the
+// moment, as opposed to the resource not existing or the credentials lacking access to it.
+func isHttpSourceUnavailable(err error) bool {
+	var statusErr *httpSourceStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.statusCode == http.StatusTooManyRequests || statusErr.statusCode >= 500
+	}
+	return err != nil
+}
+
+// httpCacheId derives the cache id of a URL. The URL itself is unsuitable as a file name and
+// may contain credentials.
+func httpCacheId(resourceUrl string) string {
+	sum := sha256.Sum256([]byte(resourceUrl))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testEnvironmentFile = `{"id":"e1","name":"staging","values":[{"key":"baseUrl","value":"https://staging.example.com","enabled":true}]}`
+
+// newTestCollectionServer serves a collection and an environment with an ETag, answers
+// conditional requests with 304 and counts the full downloads.
+func newTestCollectionServer(t *testing.T, available *atomic.Bool, downloads *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads.Add(1)
+		switch r.URL.Path {
+		case "/shop.postman_collection.json":
+			_, _ = w.Write([]byte(testCollectionFile))
+		case "/staging.postman_environment.json":
+			_, _ = w.Write([]byte(testEnvironmentFile))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHttpSourceDiscoversCollectionsWithConditionalRequests(t *testing.T) {
+	var available atomic.Bool
+	var downloads atomic.Int32
+	available.Store(true)
+	server := newTestCollectionServer(t, &available, &downloads)
+	cache := useTestCache(t, time.Hour)
+	source := httpSource{
+		collectionUrls:  []string{server.URL + "/shop.postman_collection.json"},
+		environmentUrls: []string{server.URL + "/staging.postman_environment.json"},
+		bearerToken:     "test-token",
+	}
+
+	targets, err := source.discoverCollections()
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	assert.Equal(t, "http:"+server.URL+"/shop.postman_collection.json", targets[0].Id)
+	assert.Equal(t, map[string][]string{
+		"postman.collection.id":     {"8f1e5a7c-0000-4000-8000-000000000001"},
+		"postman.collection.name":   {"shop"},
+		"postman.collection.source": {"http"},
+		"postman.collection.url":    {server.URL + "/shop.postman_collection.json"},
+
+		"postman.collection.request-count":   {"1"},
+		"postman.collection.assertion-count": {"0"},
+		"postman.collection.has-tests":       {"false"},
+	}, targets[0].Attributes)
+	assert.Equal(t, int32(2), downloads.Load())
+
+	_, err = source.discoverCollections()
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), downloads.Load(), "unchanged files are not downloaded again")
+	assert.Equal(t, int64(2), cache.hits.Load())
+	assert.Equal(t, int64(2), cache.misses.Load(), "the first downloads are cache misses")
+
+	collectionPath := filepath.Join(t.TempDir(), "collection.json")
+	messages, err := source.fetchCollection(context.Background(), targets[0].Attributes, collectionPath)
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+	content, err := os.ReadFile(collectionPath)
+	require.NoError(t, err)
+	assert.JSONEq(t, testCollectionFile, string(content))
+
+	environmentPath := filepath.Join(t.TempDir(), "environment.json")
+	_, err = source.fetchEnvironment(context.Background(), targets[0].Attributes, "staging", environmentPath)
+	require.NoError(t, err)
+	content, err = os.ReadFile(environmentPath)
+	require.NoError(t, err)
+	assert.JSONEq(t, testEnvironmentFile, string(content))
+}
+
+func TestHttpSourceFallsBackToCache(t *testing.T) {
+	var available atomic.Bool
+	var downloads atomic.Int32
+	available.Store(true)
+	server := newTestCollectionServer(t, &available, &downloads)
+	useTestCache(t, time.Hour)
+	source := httpSource{collectionUrls: []string{server.URL + "/shop.postman_collection.json"}, bearerToken: "test-token"}
+	targets, err := source.discoverCollections()
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+
+	available.Store(false)
+	messages, err := source.fetchCollection(context.Background(), targets[0].Attributes, filepath.Join(t.TempDir(), "collection.json"))
+
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Contains(t, messages[0].Message, "using the cached version")
+}
+
+func TestHttpSourceRejectsUnauthorizedAndUnconfiguredUrls(t *testing.T) {
+	var available atomic.Bool
+	var downloads atomic.Int32
+	available.Store(true)
+	server := newTestCollectionServer(t, &available, &downloads)
+	useTestCache(t, time.Hour)
+	source := httpSource{collectionUrls: []string{server.URL + "/shop.postman_collection.json"}, username: "user", password: "wrong"}
+
+	targets, err := source.discoverCollections()
+	assert.ErrorContains(t, err, "401")
+	assert.Empty(t, targets)
+
+	_, err = source.fetchCollection(context.Background(), map[string][]string{"postman.collection.url": {"https://example.com/other.json"}}, filepath.Join(t.TempDir(), "collection.json"))
+	assert.ErrorContains(t, err, "collection url https://example.com/other.json is not configured")
+}
+
+func TestHttpSourceReportsFailedUrlsAlongWithTheDiscoveredCollections(t *testing.T) {
+	var available atomic.Bool
+	var downloads atomic.Int32
+	available.Store(true)
+	server := newTestCollectionServer(t, &available, &downloads)
+	useTestCache(t, time.Hour)
+	source := httpSource{
+		collectionUrls: []string{server.URL + "/shop.postman_collection.json", server.URL + "/gone.postman_collection.json"},
+		bearerToken:    "test-token",
+	}
+
+	targets, err := source.discoverCollections()
+
+	assert.ErrorContains(t, err, "failed to download collection "+server.URL+"/gone.postman_collection.json")
+	require.Len(t, targets, 1)
+	assert.Equal(t, "http:"+server.URL+"/shop.postman_collection.json", targets[0].Id)
+}
+
+func TestHttpSourceSkipsUnavailableEnvironments(t *testing.T) {
+	var available atomic.Bool
+	var downloads atomic.Int32
+	available.Store(true)
+	server := newTestCollectionServer(t, &available, &downloads)
+	useTestCache(t, time.Hour)
+	source := httpSource{
+		environmentUrls: []string{server.URL + "/gone.postman_environment.json", server.URL + "/staging.postman_environment.json"},
+		bearerToken:     "test-token",
+	}
+
+	environmentPath := filepath.Join(t.TempDir(), "environment.json")
+	_, err := source.fetchEnvironment(context.Background(), nil, "staging", environmentPath)
+	require.NoError(t, err)
+	content, err := os.ReadFile(environmentPath)
+	require.NoError(t, err)
+	assert.JSONEq(t, testEnvironmentFile, string(content))
+
+	_, err = source.fetchEnvironment(context.Background(), nil, "production", environmentPath)
+	assert.ErrorContains(t, err, "failed to find environment with id or name 'production'")
+	assert.ErrorContains(t, err, "failed to download "+server.URL+"/gone.postman_environment.json")
+}
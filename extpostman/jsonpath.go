@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a compiled JSONPath expression. Only the subset needed to select fields of
+// request and response bodies is supported: $, .name, ['name'], [n], [*], .* and ..name.
+type jsonPath struct {
+	expression string
+	segments   []jsonPathSegment
+}
+
+type jsonPathSegment struct {
+	name      string
+	index     int
+	wildcard  bool
+	isIndex   bool
+	recursive bool
+}
+
+func compileJsonPath(expression string) (jsonPath, error) {
+	path := jsonPath{expression: expression}
+	rest := strings.TrimSpace(expression)
+	if !strings.HasPrefix(rest, "$") {
+		return path, fmt.Errorf("invalid JSONPath %q: must start with $", expression)
+	}
+	rest = rest[1:]
+
+	for len(rest) > 0 {
+		var segment jsonPathSegment
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			segment.recursive = true
+			rest = rest[2:]
+			if strings.HasPrefix(rest, "[") {
+				break
+			}
+			name, remainder := readJsonPathName(rest)
+			if name == "" {
+				return path, fmt.Errorf("invalid JSONPath %q: missing name after ..", expression)
+			}
+			segment.name, segment.wildcard = name, name == "*"
+			rest = remainder
+			path.segments = append(path.segments, segment)
+			continue
+		case strings.HasPrefix(rest, "."):
+			name, remainder := readJsonPathName(rest[1:])
+			if name == "" {
+				return path, fmt.Errorf("invalid JSONPath %q: missing name after .", expression)
+			}
+			segment.name, segment.wildcard = name, name == "*"
+			rest = remainder
+			path.segments = append(path.segments, segment)
+			continue
+		}
+
+		if !strings.HasPrefix(rest, "[") {
+			return path, fmt.Errorf("invalid JSONPath %q: unexpected %q", expression, rest)
+		}
+		end := strings.Index(rest, "]")
+		if end < 0 {
+			return path, fmt.Errorf("invalid JSONPath %q: missing ]", expression)
+		}
+		selector := strings.TrimSpace(rest[1:end])
+		rest = rest[end+1:]
+		switch {
+		case selector == "*":
+			segment.wildcard = true
+		case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+			segment.name = selector[1 : len(selector)-1]
+		default:
+			index, err := strconv.Atoi(selector)
+			if err != nil {
+				return path, fmt.Errorf("invalid JSONPath %q: unsupported selector [%s]", expression, selector)
+			}
+			segment.index, segment.isIndex = index, true
+		}
+		path.segments = append(path.segments, segment)
+	}
+	return path, nil
+}
+
+func readJsonPathName(s string) (string, string) {
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+// find returns all values of the decoded JSON document selected by the path.
+func (p jsonPath) find(document any) []any {
+	current := []any{document}
+	for _, segment := range p.segments {
+		var next []any
+		for _, value := range current {
+			if segment.recursive {
+				for _, descendant := range jsonDescendants(value) {
+					next = append(next, segment.apply(descendant)...)
+				}
+			} else {
+				next = append(next, segment.apply(value)...)
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+func (s jsonPathSegment) apply(value any) []any {
+	switch typed := value.(type) {
+	case map[string]any:
+		if s.wildcard {
+			result := make([]any, 0, len(typed))
+			for _, child := range typed {
+				result = append(result, child)
+			}
+			return result
+		}
+		if child, ok := typed[s.name]; ok && !s.isIndex {
+			return []any{child}
+		}
+	case []any:
+		if s.wildcard {
+			return typed
+		}
+		if s.isIndex {
+			index := s.index
+			if index < 0 {
+				index += len(typed)
+			}
+			if index >= 0 && index < len(typed) {
+				return []any{typed[index]}
+			}
+		}
+	}
+	return nil
+}
+
+// jsonDescendants returns the value itself and all values nested in it.
+func jsonDescendants(value any) []any {
+	result := []any{value}
+	switch typed := value.(type) {
+	case map[string]any:
+		for _, child := range typed {
+			result = append(result, jsonDescendants(child)...)
+		}
+	case []any:
+		for _, child := range typed {
+			result = append(result, jsonDescendants(child)...)
+		}
+	}
+	return result
+}
@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+	"github.com/steadybit/extension-kit/exthttp"
+)
+
+var (
+	runDurationBuckets       = []float64{1, 5, 10, 30, 60, 120, 300, 600, 1800, 3600}
+	discoveryDurationBuckets = []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300}
+	apiLatencyBuckets        = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+	metricRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "postman_collection_runs_total",
+		Help: "Finished collection runs by verdict.",
+	}, []string{"verdict"})
+	metricRunDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "postman_collection_run_duration_seconds",
+		Help:    "Duration of finished collection runs, including retries.",
+		Buckets: runDurationBuckets,
+	}, []string{"verdict"})
+	metricNewmanExitCodes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "postman_newman_exit_codes_total",
+		Help: "Exit codes of finished newman processes.",
+	}, []string{"exit_code"})
+	metricActiveRuns = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "postman_collection_runs_active",
+		Help: "Collection runs in progress.",
+	})
+	metricDiscoveryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "postman_discovery_duration_seconds",
+		Help:    "Duration of the discovery of a source.",
+		Buckets: discoveryDurationBuckets,
+	}, []string{"discovery", "source"})
+	metricDiscoveryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "postman_discovery_errors_total",
+		Help: "Failed discoveries of a source.",
+	}, []string{"discovery", "source"})
+	metricDiscoveredTargets = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "postman_discovered_targets",
+		Help: "Targets reported by the last discovery of a source, including kept targets of failed discoveries.",
+	}, []string{"discovery", "source"})
+	metricApiRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "postman_api_requests_total",
+		Help: "Requests to the Postman API by endpoint and status code, each retry counting as request.",
+	}, []string{"endpoint", "status_code"})
+	metricApiLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "postman_api_request_duration_seconds",
+		Help:    "Latency of requests to the Postman API.",
+		Buckets: apiLatencyBuckets,
+	}, []string{"endpoint"})
+
+	metricsHandler = promhttp.Handler()
+)
+
+// postmanApiEndpoint returns the method and path of the request with the ids replaced, e.g.
+// GET /collections/{id}, so the metrics of the requests don't grow with the number of resources.
+func postmanApiEndpoint(req *http.Request) string {
+	resources := []string{collectionsResource, environmentsResource, monitorsResource, workspacesResource}
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	for i := 1; i < len(segments); i++ {
+		if slices.Contains(resources, segments[i-1]) {
+			segments[i] = "{id}"
+		}
+	}
+	return req.Method + " /" + strings.Join(segments, "/")
+}
+
+// observePostmanApiRequest counts the request by its status code, or as error if it failed
+// without response.
+func observePostmanApiRequest(endpoint string, response *http.Response, err error, latency time.Duration) {
+	statusCode := "error"
+	if err == nil {
+		statusCode = strconv.Itoa(response.StatusCode)
+	}
+	metricApiRequests.WithLabelValues(endpoint, statusCode).Inc()
+	metricApiLatency.WithLabelValues(endpoint).Observe(latency.Seconds())
+}
+
+// observeDiscovery records the duration and errors of the discovery of a source and the number of
+// targets reported for it, including the targets kept from previous discoveries.
+func observeDiscovery(discovery, source string, started time.Time, reported []discovery_kit_api.Target, err error) {
+	metricDiscoveryDuration.WithLabelValues(discovery, source).Observe(time.Since(started).Seconds())
+	if err != nil {
+		metricDiscoveryErrors.WithLabelValues(discovery, source).Inc()
+	}
+	metricDiscoveredTargets.WithLabelValues(discovery, source).Set(float64(len(reported)))
+}
+
+// observeRun records the verdict and duration of a finished step, which runs the collection in
+// one or, as environment matrix, in several environments.
+func observeRun(summary RunSummary) {
+	metricRuns.WithLabelValues(summary.Verdict).Inc()
+	if !summary.StartedAt.IsZero() {
+		metricRunDuration.WithLabelValues(summary.Verdict).Observe(summary.EndedAt.Sub(summary.StartedAt).Seconds())
+	}
+}
+
+func observeNewmanExitCode(exitCode int) {
+	// -1 if newman was killed
+	if exitCode >= 0 {
+		metricNewmanExitCodes.WithLabelValues(strconv.Itoa(exitCode)).Inc()
+	}
+}
+
+// RegisterMetricsHandlers exposes the metrics, including the Go runtime and process metrics, in
+// the Prometheus text format via GET /metrics.
+func RegisterMetricsHandlers() {
+	exthttp.RegisterHttpHandlerWithLogLevel("GET /metrics", getMetrics, zerolog.DebugLevel)
+}
+
+func getMetrics(w http.ResponseWriter, r *http.Request, _ []byte) {
+	metricsHandler.ServeHTTP(w, r)
+}
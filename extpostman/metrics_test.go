@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostmanApiEndpoint(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/workspaces":             "GET /workspaces",
+		"/collections/c1":         "GET /collections/{id}",
+		"/monitors/m1/run":        "GET /monitors/{id}/run",
+		"/environments?workspace": "GET /environments",
+	} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		assert.Equal(t, expected, postmanApiEndpoint(req), path)
+	}
+}
+
+func TestPostmanApiRequestsAreCounted(t *testing.T) {
+	useFastPostmanApiRetries(t)
+	var requests atomic.Int32
+	account := newScriptedPostmanApiStub(t, &requests, nil, http.StatusServiceUnavailable, http.StatusOK)
+	unavailable := testutil.ToFloat64(metricApiRequests.WithLabelValues("GET /workspaces", "503"))
+	ok := testutil.ToFloat64(metricApiRequests.WithLabelValues("GET /workspaces", "200"))
+
+	_, err := getPostmanWorkspaces(account)
+
+	require.NoError(t, err)
+	assert.Equal(t, unavailable+1, testutil.ToFloat64(metricApiRequests.WithLabelValues("GET /workspaces", "503")))
+	assert.Equal(t, ok+1, testutil.ToFloat64(metricApiRequests.WithLabelValues("GET /workspaces", "200")))
+
+	recorder := httptest.NewRecorder()
+	getMetrics(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil), nil)
+	assert.Contains(t, recorder.Body.String(), `postman_api_request_duration_seconds_count{endpoint="GET /workspaces"}`)
+	assert.Contains(t, recorder.Body.String(), "postman_collection_runs_active ")
+}
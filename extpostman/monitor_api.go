@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"net/http"
+)
+
+const monitorsResource = "monitors"
+
+// monitorRunHttpClient runs monitors, which the Postman API answers only once the run finished.
+// The requests are bounded by their context instead of a client timeout.
+var monitorRunHttpClient = &http.Client{}
+
+type PostmanMonitorResult struct {
+	Monitors []PostmanMonitor `json:"monitors"`
+}
+
+type PostmanMonitorDetailResult struct {
+	Monitor PostmanMonitor `json:"monitor"`
+}
+
+type PostmanMonitor struct {
+	Id             string `json:"id"`
+	Name           string `json:"name"`
+	Uid            string `json:"uid"`
+	CollectionUid  string `json:"collectionUid"`
+	EnvironmentUid string `json:"environmentUid"`
+	UpdatedAt      string `json:"updatedAt"`
+	// Schedule is only part of the monitor details.
+	Schedule *PostmanMonitorSchedule `json:"schedule,omitempty"`
+}
+
+type PostmanMonitorSchedule struct {
+	Cron     string `json:"cron"`
+	Timezone string `json:"timezone"`
+	NextRun  string `json:"nextRun"`
+}
+
+type PostmanMonitorRunResult struct {
+	Run PostmanMonitorRun `json:"run"`
+}
+
+type PostmanMonitorRun struct {
+	Info struct {
+		JobId      string `json:"jobId"`
+		MonitorId  string `json:"monitorId"`
+		Name       string `json:"name"`
+		Status     string `json:"status"`
+		StartedAt  string `json:"startedAt"`
+		FinishedAt string `json:"finishedAt"`
+	} `json:"info"`
+	Stats struct {
+		Assertions PostmanMonitorRunStat `json:"assertions"`
+		Requests   PostmanMonitorRunStat `json:"requests"`
+	} `json:"stats"`
+	Executions []PostmanMonitorExecution `json:"executions"`
+	Failures   []PostmanMonitorFailure   `json:"failures"`
+}
+
+type PostmanMonitorRunStat struct {
+	Total  int `json:"total"`
+	Failed int `json:"failed"`
+}
+
+type PostmanMonitorExecution struct {
+	Id   int `json:"id"`
+	Item struct {
+		Id   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"item"`
+	Request struct {
+		Method string `json:"method"`
+		Url    string `json:"url"`
+	} `json:"request"`
+	Response struct {
+		Code         int   `json:"code"`
+		ResponseTime int64 `json:"responseTime"`
+		ResponseSize int64 `json:"responseSize"`
+	} `json:"response"`
+}
+
+type PostmanMonitorFailure struct {
+	ExecutionId int    `json:"executionId"`
+	Name        string `json:"name"`
+	Message     string `json:"message"`
+	Error       *struct {
+		Name    string `json:"name"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+	Assertion map[string]bool `json:"assertion,omitempty"`
+}
+
+func getPostmanMonitors(account postmanAccount) ([]PostmanMonitor, error) {
+	var result PostmanMonitorResult
+	if err := callPostmanApi(postmanHttpClient, context.Background(), account, http.MethodGet, nil, &result, monitorsResource); err != nil {
+		return nil, err
+	}
+	return result.Monitors, nil
+}
+
+// getPostmanMonitor returns the details of the monitor, including its schedule.
+func getPostmanMonitor(account postmanAccount, monitorId string) (*PostmanMonitor, error) {
+	var result PostmanMonitorDetailResult
+	if err := callPostmanApi(postmanHttpClient, context.Background(), account, http.MethodGet, nil, &result, monitorsResource, monitorId); err != nil {
+		return nil, err
+	}
+	return &result.Monitor, nil
+}
+
+// runPostmanMonitor runs the monitor on the Postman infrastructure and waits for its result.
+func runPostmanMonitor(ctx context.Context, account postmanAccount, monitorId string) (*PostmanMonitorRun, error) {
+	var result PostmanMonitorRunResult
+	if err := callPostmanApi(monitorRunHttpClient, ctx, account, http.MethodPost, nil, &result, monitorsResource, monitorId, "run"); err != nil {
+		return nil, err
+	}
+	return &result.Run, nil
+}
@@ -0,0 +1,317 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_commons"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_sdk"
+	"github.com/steadybit/extension-kit/extbuild"
+	"github.com/steadybit/extension-postman/v2/config"
+)
+
+const (
+	monitorTargetID = "com.steadybit.extension_postman.monitor"
+
+	attributeMonitorId              = "postman.monitor.id"
+	attributeMonitorName            = "postman.monitor.name"
+	attributeMonitorCollectionUid   = "postman.monitor.collection.uid"
+	attributeMonitorCollectionName  = "postman.monitor.collection.name"
+	attributeMonitorEnvironmentUid  = "postman.monitor.environment.uid"
+	attributeMonitorEnvironmentName = "postman.monitor.environment.name"
+	attributeMonitorSchedule        = "postman.monitor.schedule"
+	attributeMonitorTimezone        = "postman.monitor.schedule.timezone"
+)
+
+// monitorScheduleMaxAge is how long the schedule of a monitor is reused if the Postman API
+// does not tell when the monitor was updated.
+const monitorScheduleMaxAge = 24 * time.Hour
+
+type monitorDiscovery struct {
+}
+
+// monitorSchedules remembers the schedules of the monitors by account and id, so discovery
+// requests the details of a monitor only once it is new or its updatedAt changed.
+var (
+	monitorSchedules     = make(map[string]monitorSchedule)
+	monitorSchedulesLock sync.Mutex
+)
+
+type monitorSchedule struct {
+	updatedAt string
+	fetchedAt time.Time
+	schedule  *PostmanMonitorSchedule
+}
+
+var (
+	_ discovery_kit_sdk.TargetDescriber    = (*monitorDiscovery)(nil)
+	_ discovery_kit_sdk.AttributeDescriber = (*monitorDiscovery)(nil)
+)
+
+func NewPostmanMonitorDiscovery() discovery_kit_sdk.TargetDiscovery {
+	discovery := &monitorDiscovery{}
+	interval, err := time.ParseDuration(config.Config.PostmanCollectionDiscoveryInterval)
+	if err != nil {
+		log.Error().Msgf("Failed to parse Postman collection discovery interval: %s", err)
+		return nil
+	}
+	return discovery_kit_sdk.NewCachedTargetDiscovery(discovery,
+		discovery_kit_sdk.WithRefreshTargetsNow(),
+		discovery_kit_sdk.WithRefreshTargetsInterval(context.Background(), interval),
+	)
+}
+
+func (d *monitorDiscovery) Describe() discovery_kit_api.DiscoveryDescription {
+	return discovery_kit_api.DiscoveryDescription{
+		Id: monitorTargetID,
+		Discover: discovery_kit_api.DescribingEndpointReferenceWithCallInterval{
+			CallInterval: new("1m"),
+		},
+	}
+}
+
+func (d *monitorDiscovery) DescribeTarget() discovery_kit_api.TargetDescription {
+	return discovery_kit_api.TargetDescription{
+		Id:       monitorTargetID,
+		Version:  extbuild.GetSemverVersionStringOrUnknown(),
+		Icon:     new(icon),
+		Label:    discovery_kit_api.PluralLabel{One: "Postman Monitor", Other: "Postman Monitors"},
+		Category: new("postman"),
+		Table: discovery_kit_api.Table{
+			Columns: []discovery_kit_api.Column{
+				{Attribute: attributeMonitorName},
+				{Attribute: attributeMonitorCollectionName},
+				{Attribute: attributeMonitorEnvironmentName},
+				{Attribute: attributeMonitorSchedule},
+			},
+			OrderBy: []discovery_kit_api.OrderBy{
+				{
+					Attribute: attributeMonitorName,
+					Direction: "ASC",
+				},
+			},
+		},
+	}
+}
+
+func (d *monitorDiscovery) DescribeAttributes() []discovery_kit_api.AttributeDescription {
+	return []discovery_kit_api.AttributeDescription{
+		{
+			Attribute: attributeMonitorName,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Monitor Name",
+				Other: "Monitor Names",
+			},
+		},
+		{
+			Attribute: attributeMonitorId,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Monitor Id",
+				Other: "Monitor Ids",
+			},
+		},
+		{
+			Attribute: attributeMonitorCollectionUid,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Monitor Collection Uid",
+				Other: "Monitor Collection Uids",
+			},
+		},
+		{
+			Attribute: attributeMonitorCollectionName,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Monitor Collection Name",
+				Other: "Monitor Collection Names",
+			},
+		},
+		{
+			Attribute: attributeMonitorEnvironmentUid,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Monitor Environment Uid",
+				Other: "Monitor Environment Uids",
+			},
+		},
+		{
+			Attribute: attributeMonitorEnvironmentName,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Monitor Environment Name",
+				Other: "Monitor Environment Names",
+			},
+		},
+		{
+			Attribute: attributeMonitorSchedule,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Monitor Schedule",
+				Other: "Monitor Schedules",
+			},
+		},
+		{
+			Attribute: attributeMonitorTimezone,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Monitor Timezone",
+				Other: "Monitor Timezones",
+			},
+		},
+		{
+			Attribute: attributeDiscoveryStaleness,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Discovery Staleness",
+				Other: "Discovery Staleness",
+			},
+		},
+		{
+			Attribute: attributeAccount,
+			Label: discovery_kit_api.PluralLabel{
+				One:   "Postman Account",
+				Other: "Postman Accounts",
+			},
+		},
+	}
+}
+
+func (d *monitorDiscovery) DiscoverTargets(_ context.Context) ([]discovery_kit_api.Target, error) {
+	targets, err := discoverMonitors()
+	if err != nil {
+		return nil, err
+	}
+	return discovery_kit_commons.ApplyAttributeExcludes(targets, []string{}), nil
+}
+
+// discoverMonitors lists the monitors of all accounts. Like the collections, the monitors of an
+// account whose discovery fails are kept as discovered last.
+func discoverMonitors() ([]discovery_kit_api.Target, error) {
+	targets := make([]discovery_kit_api.Target, 0)
+	var errs []error
+	for _, account := range getPostmanAccounts() {
+		started := time.Now()
+		accountTargets, discoveryErr := discoverAccountMonitors(account)
+		accountTargets, err := lastDiscoveredMonitors.update(account.name, accountTargets, discoveryErr)
+		observeDiscovery("monitors", sourceApi+"/"+account.name, started, accountTargets, discoveryErr)
+		if err != nil {
+			log.Error().Msgf("Failed to discover monitors of account %s: %s", account.name, err)
+			errs = append(errs, fmt.Errorf("failed to discover monitors of account %s: %w", account.name, err))
+			continue
+		}
+		targets = append(targets, accountTargets...)
+	}
+	if len(targets) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return targets, nil
+}
+
+// discoverAccountMonitors lists the monitors of the account. The schedule is only part of the
+// monitor details, and the names of the collection and environment are looked up by their uid.
+func discoverAccountMonitors(account postmanAccount) ([]discovery_kit_api.Target, error) {
+	var targets []discovery_kit_api.Target
+	monitors, err := getPostmanMonitors(account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monitors: %w", err)
+	}
+	forgetMonitorSchedules(account, monitors)
+	if len(monitors) == 0 {
+		return targets, nil
+	}
+
+	collections, err := GetPostmanCollections(account)
+	if err != nil {
+		return nil, err
+	}
+	collectionNames := make(map[string]string)
+	for _, collection := range collections {
+		collectionNames[collection.Uid] = collection.Name
+	}
+	environments, err := GetPostmanEnvironments(account)
+	if err != nil {
+		return nil, err
+	}
+	environmentNames := make(map[string]string)
+	for _, environment := range environments {
+		environmentNames[environment.Uid] = environment.Name
+	}
+
+	for _, monitor := range monitors {
+		attributes := map[string][]string{
+			attributeMonitorId:   {monitor.Id},
+			attributeMonitorName: {monitor.Name},
+			attributeAccount:     {account.name},
+		}
+		if monitor.CollectionUid != "" {
+			attributes[attributeMonitorCollectionUid] = []string{monitor.CollectionUid}
+			if name := collectionNames[monitor.CollectionUid]; name != "" {
+				attributes[attributeMonitorCollectionName] = []string{name}
+			}
+		}
+		if monitor.EnvironmentUid != "" {
+			attributes[attributeMonitorEnvironmentUid] = []string{monitor.EnvironmentUid}
+			if name := environmentNames[monitor.EnvironmentUid]; name != "" {
+				attributes[attributeMonitorEnvironmentName] = []string{name}
+			}
+		}
+		schedule, err := getMonitorSchedule(account, monitor)
+		if err != nil {
+			log.Warn().Msgf("Failed to get schedule of monitor %s: %s", monitor.Id, err)
+		} else if schedule != nil {
+			if schedule.Cron != "" {
+				attributes[attributeMonitorSchedule] = []string{schedule.Cron}
+			}
+			if schedule.Timezone != "" {
+				attributes[attributeMonitorTimezone] = []string{schedule.Timezone}
+			}
+		}
+		targets = append(targets, discovery_kit_api.Target{
+			Id:         accountTargetId(account, monitor.Id),
+			TargetType: monitorTargetID,
+			Label:      monitor.Name,
+			Attributes: attributes,
+		})
+	}
+	return targets, nil
+}
+
+// getMonitorSchedule returns the schedule of the monitor, which only its details contain. The
+// details are requested once the monitor is new or its updatedAt changed, as each of them counts
+// against the monthly quota of the Postman API. If the API does not tell the updatedAt, they are
+// requested again after monitorScheduleMaxAge.
+func getMonitorSchedule(account postmanAccount, monitor PostmanMonitor) (*PostmanMonitorSchedule, error) {
+	key := account.name + "/" + monitor.Id
+	monitorSchedulesLock.Lock()
+	known, ok := monitorSchedules[key]
+	monitorSchedulesLock.Unlock()
+	if ok && known.updatedAt == monitor.UpdatedAt && (monitor.UpdatedAt != "" || time.Since(known.fetchedAt) < monitorScheduleMaxAge) {
+		return known.schedule, nil
+	}
+
+	details, err := getPostmanMonitor(account, monitor.Id)
+	if err != nil {
+		return nil, err
+	}
+	monitorSchedulesLock.Lock()
+	monitorSchedules[key] = monitorSchedule{updatedAt: monitor.UpdatedAt, fetchedAt: time.Now(), schedule: details.Schedule}
+	monitorSchedulesLock.Unlock()
+	return details.Schedule, nil
+}
+
+// forgetMonitorSchedules removes the schedules of the monitors of the account that were deleted.
+func forgetMonitorSchedules(account postmanAccount, monitors []PostmanMonitor) {
+	existing := make(map[string]bool, len(monitors))
+	for _, monitor := range monitors {
+		existing[account.name+"/"+monitor.Id] = true
+	}
+	monitorSchedulesLock.Lock()
+	defer monitorSchedulesLock.Unlock()
+	for key := range monitorSchedules {
+		if strings.HasPrefix(key, account.name+"/") && !existing[key] {
+			delete(monitorSchedules, key)
+		}
+	}
+}
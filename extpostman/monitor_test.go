@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-kit/extutil"
+	"github.com/steadybit/extension-postman/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMonitorRun = `{"run":{"info":{"jobId":"j1","monitorId":"m1","name":"uptime","status":"failed"},
+"stats":{"assertions":{"total":2,"failed":1},"requests":{"total":2,"failed":0}},
+"executions":[
+ {"id":1,"item":{"id":"i1","name":"Get health"},"request":{"method":"GET","url":"https://example.com/health"},"response":{"code":200,"responseTime":87}},
+ {"id":2,"item":{"id":"i2","name":"Get orders"},"request":{"method":"GET","url":"https://example.com/orders"},"response":{"code":500,"responseTime":12}}],
+"failures":[{"executionId":2,"error":{"name":"AssertionError","message":"expected response to have status code 200 but got 500"}}]}}`
+
+func newPostmanMonitorApiStub(t *testing.T) {
+	t.Helper()
+	newCountingPostmanMonitorApiStub(t, &atomic.Value{}, &atomic.Int32{})
+}
+
+// newCountingPostmanMonitorApiStub lists the monitor with the given updatedAt and counts the
+// requests of its details.
+func newCountingPostmanMonitorApiStub(t *testing.T, updatedAt *atomic.Value, detailRequests *atomic.Int32) {
+	t.Helper()
+	monitorSchedules = make(map[string]monitorSchedule)
+	t.Cleanup(func() { monitorSchedules = make(map[string]monitorSchedule) })
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "123456", r.Header.Get("X-API-Key"))
+		switch r.Method + " " + r.URL.Path {
+		case "GET /monitors":
+			monitorUpdatedAt, _ := updatedAt.Load().(string)
+			_, _ = w.Write([]byte(`{"monitors":[{"id":"m1","name":"uptime","uid":"1-m1","collectionUid":"1-c1","environmentUid":"1-e1","updatedAt":"` + monitorUpdatedAt + `"}]}`))
+		case "GET /monitors/m1":
+			detailRequests.Add(1)
+			_, _ = w.Write([]byte(`{"monitor":{"id":"m1","name":"uptime","schedule":{"cron":"*/5 * * * *","timezone":"Europe/Berlin"}}}`))
+		case "GET /collections":
+			_, _ = w.Write([]byte(`{"collections":[{"id":"c1","uid":"1-c1","name":"shop"}]}`))
+		case "GET /environments":
+			_, _ = w.Write([]byte(`{"environments":[{"id":"e1","uid":"1-e1","name":"prod"}]}`))
+		case "POST /monitors/m1/run":
+			_, _ = w.Write([]byte(testMonitorRun))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_API_KEY", "123456")
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_BASE_URL", server.URL)
+	config.ParseConfiguration()
+}
+
+func TestDiscoverMonitors(t *testing.T) {
+	newPostmanMonitorApiStub(t)
+
+	targets, err := discoverMonitors()
+
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	assert.Equal(t, "m1", targets[0].Id)
+	assert.Equal(t, "com.steadybit.extension_postman.monitor", targets[0].TargetType)
+	assert.Equal(t, map[string][]string{
+		"postman.monitor.id":                {"m1"},
+		"postman.monitor.name":              {"uptime"},
+		"postman.monitor.collection.uid":    {"1-c1"},
+		"postman.monitor.collection.name":   {"shop"},
+		"postman.monitor.environment.uid":   {"1-e1"},
+		"postman.monitor.environment.name":  {"prod"},
+		"postman.monitor.schedule":          {"*/5 * * * *"},
+		"postman.monitor.schedule.timezone": {"Europe/Berlin"},
+		"postman.account":                   {"default"},
+	}, targets[0].Attributes)
+}
+
+func TestDiscoverMonitorsRequestsSchedulesOnlyOfNewOrUpdatedMonitors(t *testing.T) {
+	var updatedAt atomic.Value
+	var detailRequests atomic.Int32
+	updatedAt.Store("2026-10-01T08:00:00.000Z")
+	newCountingPostmanMonitorApiStub(t, &updatedAt, &detailRequests)
+
+	for range 2 {
+		targets, err := discoverMonitors()
+		require.NoError(t, err)
+		require.Len(t, targets, 1)
+		assert.Equal(t, []string{"*/5 * * * *"}, targets[0].Attributes["postman.monitor.schedule"])
+	}
+	assert.Equal(t, int32(1), detailRequests.Load())
+
+	updatedAt.Store("2026-10-02T08:00:00.000Z")
+	_, err := discoverMonitors()
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), detailRequests.Load())
+}
+
+func TestRunMonitorMapsFailuresToVerdict(t *testing.T) {
+	newPostmanMonitorApiStub(t)
+	action := NewPostmanMonitorAction()
+	state := action.NewEmptyState()
+	requestBody := extutil.JsonMangle(action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{"duration": 30000},
+		Target: &action_kit_api.Target{Attributes: map[string][]string{
+			"postman.monitor.id":   {"m1"},
+			"postman.monitor.name": {"uptime"},
+		}},
+	})
+
+	_, err := action.Prepare(context.TODO(), &state, requestBody)
+	require.NoError(t, err)
+	_, err = action.Start(context.TODO(), &state)
+	require.NoError(t, err)
+
+	var result *action_kit_api.StatusResult
+	require.Eventually(t, func() bool {
+		result, err = action.(PostmanMonitorAction).Status(context.TODO(), &state)
+		require.NoError(t, err)
+		return result.Completed
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NotNil(t, result.Error)
+	assert.Equal(t, action_kit_api.Failed, *result.Error.Status)
+	assert.Equal(t, "1 assertions failed", result.Error.Title)
+	messages := *result.Messages
+	require.Len(t, messages, 4)
+	assert.Equal(t, "GET https://example.com/orders [500, 12ms]", messages[1].Message)
+	assert.Equal(t, action_kit_api.Error, *messages[2].Level)
+	assert.Equal(t, "expected response to have status code 200 but got 500", messages[2].Message)
+	assert.Equal(t, action_kit_api.MessageFields{"request": "Get orders", "failure": "AssertionError"}, *messages[2].Fields)
+	assert.Equal(t, "Monitor run failed: 0 of 2 requests failed, 1 of 2 assertions failed", messages[3].Message)
+
+	_, ok := monitorRuns.Load(state.RunId)
+	assert.False(t, ok, "finished runs are removed")
+}
+
+func TestGetMonitorRunVerdictOfSuccessfulRun(t *testing.T) {
+	run := &PostmanMonitorRun{}
+	run.Info.Status = "success"
+	run.Stats.Requests = PostmanMonitorRunStat{Total: 1}
+
+	verdict, messages := getMonitorRunVerdict(run)
+
+	assert.Nil(t, verdict)
+	require.Len(t, messages, 1)
+}
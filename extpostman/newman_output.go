@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-kit/extutil"
+)
+
+var (
+	ansiEscapePattern = regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]`)
+
+	// e.g. "Iteration 2/3"
+	iterationPattern = regexp.MustCompile(`^Iteration (\d+/\d+)$`)
+	// e.g. "→ Get users"
+	requestPattern = regexp.MustCompile(`^→ (.+)$`)
+	// e.g. "  GET https://example.com/users [200 OK, 1.2kB, 120ms]"
+	responsePattern = regexp.MustCompile(`^\s+[A-Z]+ \S+ \[(\d{3})[ ,\]]`)
+	// e.g. "  GET https://example.com/users [errored]"
+	requestErroredPattern = regexp.MustCompile(`^\s+[A-Z]+ \S+ \[errored]$`)
+	// e.g. "  2. Status code is 200"
+	failedAssertionPattern = regexp.MustCompile(`^\s+\d+\.\s+\S`)
+	timeoutPattern         = regexp.MustCompile(`ETIMEDOUT|ESOCKETTIMEDOUT|ESOCKETTIMEOUT`)
+)
+
+// NewmanOutputState is what the output parser remembers between two Status calls: the request
+// the current output lines belong to.
+type NewmanOutputState struct {
+	Request    string `json:"request,omitempty"`
+	Iteration  string `json:"iteration,omitempty"`
+	StatusCode string `json:"statusCode,omitempty"`
+	// Errored is set after a request errored, its next line is the error.
+	Errored bool `json:"errored,omitempty"`
+	// Summary is set once newman prints the summary table after the last request.
+	Summary bool `json:"summary,omitempty"`
+}
+
+// getStdOutMessages turns the CLI output of newman into messages. Failed assertions and request
+// errors get the Error level, request timeouts the Warn level and all lines of a request carry
+// the request name, iteration and status code as fields. The raw output is kept in the log file.
+func getStdOutMessages(state *PostmanState, lines []string) []action_kit_api.Message {
+	messages := make([]action_kit_api.Message, 0, len(lines))
+	for _, line := range lines {
+		messages = append(messages, state.Output.parseLine(line))
+	}
+	return messages
+}
+
+func (s *NewmanOutputState) parseLine(line string) action_kit_api.Message {
+	line = strings.TrimRight(ansiEscapePattern.ReplaceAllString(line, ""), " \r")
+	level := action_kit_api.Info
+
+	errored := s.Errored
+	s.Errored = false
+	switch {
+	case s.Summary:
+		// the summary repeats the failures already reported with their request
+	case strings.HasPrefix(line, "┌─"):
+		s.Summary = true
+	case iterationPattern.MatchString(line):
+		s.Iteration = iterationPattern.FindStringSubmatch(line)[1]
+	case requestPattern.MatchString(line):
+		s.Request = requestPattern.FindStringSubmatch(line)[1]
+		s.StatusCode = ""
+	case responsePattern.MatchString(line):
+		s.StatusCode = responsePattern.FindStringSubmatch(line)[1]
+	case requestErroredPattern.MatchString(line):
+		s.Errored = true
+		level = action_kit_api.Warn
+	case errored:
+		level = action_kit_api.Error
+		if timeoutPattern.MatchString(line) {
+			level = action_kit_api.Warn
+		}
+	case s.Request != "" && failedAssertionPattern.MatchString(line):
+		level = action_kit_api.Error
+	}
+
+	message := action_kit_api.Message{
+		Level:   extutil.Ptr(level),
+		Message: line,
+	}
+	if s.Request != "" && !s.Summary {
+		fields := action_kit_api.MessageFields{"request": s.Request}
+		if s.Iteration != "" {
+			fields["iteration"] = s.Iteration
+		}
+		if s.StatusCode != "" {
+			fields["statusCode"] = s.StatusCode
+		}
+		message.Fields = &fields
+	}
+	return message
+}
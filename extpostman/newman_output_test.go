@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"testing"
+
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStdOutMessagesParsesNewmanOutput(t *testing.T) {
+	state := &PostmanState{}
+	lines := []string{
+		"newman",
+		"Iteration 1/2",
+		"→ Get users",
+		"  GET https://example.com/users [200 OK, 1.2kB, 120ms]",
+		"  \x1b[32m✓\x1b[39m  Status code is 200",
+		"  1. Body contains user",
+		"→ Get orders",
+		"  GET https://example.com/orders [errored]",
+	}
+
+	messages := getStdOutMessages(state, lines)
+
+	require.Len(t, messages, len(lines))
+	assert.Equal(t, action_kit_api.Info, *messages[0].Level)
+	assert.Nil(t, messages[0].Fields)
+	assert.Equal(t, action_kit_api.MessageFields{"request": "Get users", "iteration": "1/2", "statusCode": "200"}, *messages[3].Fields)
+	assert.Equal(t, "  ✓  Status code is 200", messages[4].Message)
+	assert.Equal(t, action_kit_api.Info, *messages[4].Level)
+	assert.Equal(t, action_kit_api.Error, *messages[5].Level)
+	assert.Equal(t, action_kit_api.MessageFields{"request": "Get orders", "iteration": "1/2"}, *messages[6].Fields)
+	assert.Equal(t, action_kit_api.Warn, *messages[7].Level)
+
+	// the parser continues with the lines of the next Status call
+	messages = getStdOutMessages(state, []string{
+		"     ESOCKETTIMEDOUT",
+		"→ Get invoices",
+		"  GET https://example.com/invoices [errored]",
+		"     connect ECONNREFUSED 127.0.0.1:443",
+		"┌─────────────────────────┬──────────┬──────────┐",
+		"  1.  AssertionError  Body contains user",
+	})
+
+	assert.Equal(t, action_kit_api.Warn, *messages[0].Level)
+	assert.Equal(t, "Get orders", (*messages[0].Fields)["request"])
+	assert.Equal(t, action_kit_api.Error, *messages[3].Level)
+	assert.Equal(t, action_kit_api.Info, *messages[4].Level)
+	assert.Equal(t, action_kit_api.Info, *messages[5].Level)
+	assert.Nil(t, messages[5].Fields)
+}
@@ -0,0 +1,434 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+	"github.com/steadybit/extension-kit/extbuild"
+)
+
+const (
+	sourceOpenApi = "openapi"
+
+	attributeCollectionSpecification = "postman.collection.specification"
+
+	postmanCollectionSchemaV21 = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+	baseUrlVariable            = "baseUrl"
+)
+
+// openApiSource generates smoke-test collections from OpenAPI 3 documents. Every GET operation
+// without required parameters becomes a request asserting the documented status code and, for
+// JSON responses, the response schema. The documents are loaded again for every run, so a run
+// always tests the current version of the API description.
+type openApiSource struct {
+	// specifications are the file paths or URLs of the documents
+	specifications []string
+}
+
+func (s openApiSource) name() string {
+	return sourceOpenApi
+}
+
+func (s openApiSource) discoverCollections() ([]discovery_kit_api.Target, error) {
+	targets := make([]discovery_kit_api.Target, 0, len(s.specifications))
+	var errs []error
+	for _, specification := range s.specifications {
+		collection, err := generateOpenApiCollection(specification)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to generate a collection of OpenAPI specification %s: %w", redactUrlCredentials(specification), err))
+			continue
+		}
+		info := collection["info"].(map[string]any)
+		name := info["name"].(string)
+		target := discovery_kit_api.Target{
+			Id:         sourceOpenApi + ":" + redactUrlCredentials(specification),
+			TargetType: targetID,
+			Label:      name,
+			Attributes: map[string][]string{
+				attributeCollectionId:            {info["_postman_id"].(string)},
+				attributeCollectionName:          {name},
+				attributeCollectionSource:        {sourceOpenApi},
+				attributeCollectionSpecification: {redactUrlCredentials(specification)},
+			},
+		}
+		if content, err := json.Marshal(collection); err == nil {
+			addCollectionSummaryAttributes(&target, content)
+		}
+		targets = append(targets, target)
+	}
+	return targets, errors.Join(errs...)
+}
+
+// fetchCollection generates the collection of the target. Only configured specifications are
+// loaded, the target attribute merely selects one of them.
+func (s openApiSource) fetchCollection(_ context.Context, attributes map[string][]string, destPath string) ([]action_kit_api.Message, error) {
+	targetSpecification, err := singleAttribute(attributes, attributeCollectionSpecification)
+	if err != nil {
+		return nil, err
+	}
+	index := slices.IndexFunc(s.specifications, func(specification string) bool {
+		return redactUrlCredentials(specification) == targetSpecification
+	})
+	if index < 0 {
+		return nil, fmt.Errorf("OpenAPI specification %s is not configured", targetSpecification)
+	}
+	collection, err := generateOpenApiCollection(s.specifications[index])
+	if err != nil {
+		return nil, err
+	}
+	content, err := json.Marshal(collection)
+	if err != nil {
+		return nil, err
+	}
+	return nil, os.WriteFile(destPath, content, 0600)
+}
+
+// fetchEnvironment fails, OpenAPI documents contain no environments. The generated collections
+// define the baseUrl variable from the first server of the document instead.
+func (s openApiSource) fetchEnvironment(_ context.Context, _ map[string][]string, environmentIdOrName, _ string) ([]action_kit_api.Message, error) {
+	return nil, fmt.Errorf("failed to find environment with id or name '%s', collections generated from OpenAPI specifications have no environments", environmentIdOrName)
+}
+
+// generateOpenApiCollection loads the OpenAPI document from the file path or http(s) URL and
+// generates a Postman v2.1 collection from it.
+func generateOpenApiCollection(specification string) (map[string]any, error) {
+	document, location, err := loadOpenApiDocument(specification)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]any, 0)
+	paths := document.Paths.Map()
+	for _, path := range slices.Sorted(maps.Keys(paths)) {
+		pathItem := paths[path]
+		if pathItem.Get == nil || !isCallableWithoutParameters(path, pathItem) {
+			continue
+		}
+		items = append(items, newOpenApiRequestItem(path, pathItem.Get))
+	}
+
+	name := redactUrlCredentials(specification)
+	description := ""
+	if document.Info != nil {
+		if document.Info.Title != "" {
+			name = document.Info.Title
+		}
+		description = document.Info.Description
+	}
+	return map[string]any{
+		"info": map[string]any{
+			"_postman_id": uuid.NewSHA1(uuid.NameSpaceURL, []byte(redactUrlCredentials(specification))).String(),
+			"name":        name,
+			"description": description,
+			"schema":      postmanCollectionSchemaV21,
+		},
+		"item": items,
+		"variable": []any{
+			map[string]any{"key": baseUrlVariable, "value": openApiBaseUrl(document, location), "type": "string"},
+		},
+	}, nil
+}
+
+// loadOpenApiDocument loads the document in JSON or YAML. References to other documents are not
+// followed. It also returns the URL of the document if it was downloaded.
+func loadOpenApiDocument(specification string) (*openapi3.T, *url.URL, error) {
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = false
+
+	if !strings.HasPrefix(specification, "http://") && !strings.HasPrefix(specification, "https://") {
+		document, err := loader.LoadFromFile(specification)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load OpenAPI specification %s: %w", specification, err)
+		}
+		return document, nil, nil
+	}
+
+	location, err := url.Parse(specification)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid url %s", redactUrlCredentials(specification))
+	}
+	content, err := downloadOpenApiDocument(specification)
+	if err != nil {
+		return nil, nil, err
+	}
+	document, err := loader.LoadFromDataWithPath(content, location)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load OpenAPI specification %s: %w", redactUrlCredentials(specification), err)
+	}
+	return document, location, nil
+}
+
+func downloadOpenApiDocument(specification string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, specification, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url %s", redactUrlCredentials(specification))
+	}
+	req.Header.Add("Accept", "application/json, application/yaml, */*")
+	req.Header.Add("User-Agent", fmt.Sprintf("steadybit-extension-postman/%s", extbuild.GetSemverVersionStringOrUnknown()))
+
+	response, err := postmanHttpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request %s: %s", redactUrlCredentials(specification), urlCredentialsPattern.ReplaceAllString(err.Error(), "://"))
+	}
+	defer func() {
+		if cerr := response.Body.Close(); cerr != nil {
+			log.Error().Msgf("Failed to close response body. Got error: %s", cerr)
+		}
+	}()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s, got status code %s", redactUrlCredentials(specification), response.Status)
+	}
+	return io.ReadAll(response.Body)
+}
+
+// openApiBaseUrl returns the url of the first server of the document with its variables set to
+// their defaults. Relative urls are resolved against the location of the document.
+func openApiBaseUrl(document *openapi3.T, location *url.URL) string {
+	if len(document.Servers) == 0 || document.Servers[0] == nil {
+		return ""
+	}
+	server := document.Servers[0]
+	baseUrl := server.URL
+	for name, variable := range server.Variables {
+		if variable != nil {
+			baseUrl = strings.ReplaceAll(baseUrl, "{"+name+"}", variable.Default)
+		}
+	}
+	if location != nil {
+		if reference, err := url.Parse(baseUrl); err == nil && !reference.IsAbs() {
+			baseUrl = location.ResolveReference(reference).String()
+		}
+	}
+	return strings.TrimSuffix(redactUrlCredentials(baseUrl), "/")
+}
+
+// isCallableWithoutParameters tells whether the operation can be requested without knowing
+// values for any parameter, i.e. the path has no templates and no parameter is required.
+func isCallableWithoutParameters(path string, pathItem *openapi3.PathItem) bool {
+	if strings.Contains(path, "{") {
+		return false
+	}
+	for _, parameter := range slices.Concat(pathItem.Parameters, pathItem.Get.Parameters) {
+		if parameter != nil && parameter.Value != nil && parameter.Value.Required {
+			return false
+		}
+	}
+	return true
+}
+
+func newOpenApiRequestItem(path string, operation *openapi3.Operation) map[string]any {
+	name := operation.Summary
+	if name == "" {
+		name = operation.OperationID
+	}
+	if name == "" {
+		name = "GET " + path
+	}
+
+	status, response := expectedOpenApiResponse(operation)
+	exec := []string{}
+	if status > 0 {
+		exec = append(exec,
+			fmt.Sprintf("pm.test(\"Status code is %d\", function () {", status),
+			fmt.Sprintf("    pm.response.to.have.status(%d);", status),
+			"});",
+		)
+	} else {
+		exec = append(exec,
+			"pm.test(\"Status code is successful\", function () {",
+			"    pm.response.to.be.success;",
+			"});",
+		)
+	}
+
+	headers := []any{}
+	if schema := jsonResponseSchema(response); schema != nil {
+		headers = append(headers, map[string]any{"key": "Accept", "value": "application/json"})
+		content, err := json.Marshal(toJsonSchema(schema, make(map[*openapi3.Schema]bool)))
+		if err == nil {
+			exec = append(exec,
+				fmt.Sprintf("var schema = %s;", content),
+				"pm.test(\"Response matches schema\", function () {",
+				"    pm.response.to.have.jsonSchema(schema);",
+				"});",
+			)
+		}
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return map[string]any{
+		"name": name,
+		"request": map[string]any{
+			"method": http.MethodGet,
+			"header": headers,
+			"url": map[string]any{
+				"raw":  "{{" + baseUrlVariable + "}}" + path,
+				"host": []string{"{{" + baseUrlVariable + "}}"},
+				"path": segments,
+			},
+			"description": operation.Description,
+		},
+		"event": []any{
+			map[string]any{
+				"listen": "test",
+				"script": map[string]any{"type": "text/javascript", "exec": exec},
+			},
+		},
+	}
+}
+
+// expectedOpenApiResponse returns the lowest documented 2xx status code and its response. The
+// status code is 0 if only a range (2XX) or the default response is documented.
+func expectedOpenApiResponse(operation *openapi3.Operation) (int, *openapi3.Response) {
+	responses := operation.Responses.Map()
+	for _, code := range slices.Sorted(maps.Keys(responses)) {
+		status, err := strconv.Atoi(code)
+		if err == nil && status >= 200 && status < 300 {
+			return status, responses[code].Value
+		}
+	}
+	for _, code := range []string{"2XX", "2xx", "default"} {
+		if response := responses[code]; response != nil {
+			return 0, response.Value
+		}
+	}
+	return 0, nil
+}
+
+// jsonResponseSchema returns the schema of the JSON content of the response, if documented.
+func jsonResponseSchema(response *openapi3.Response) *openapi3.SchemaRef {
+	if response == nil {
+		return nil
+	}
+	for _, mediaType := range slices.Sorted(maps.Keys(response.Content)) {
+		base, _, _ := strings.Cut(mediaType, ";")
+		if base == "application/json" || strings.HasSuffix(base, "+json") {
+			if content := response.Content[mediaType]; content != nil && content.Schema != nil && content.Schema.Value != nil {
+				return content.Schema
+			}
+		}
+	}
+	return nil
+}
+
+// toJsonSchema converts the OpenAPI schema to a self-contained JSON schema for the jsonSchema
+// assertion of Postman. References are inlined; recursive references accept any value. Formats
+// are left out, as the validator of Postman does not know all formats used in OpenAPI.
+func toJsonSchema(ref *openapi3.SchemaRef, visiting map[*openapi3.Schema]bool) map[string]any {
+	result := map[string]any{}
+	if ref == nil || ref.Value == nil || visiting[ref.Value] {
+		return result
+	}
+	schema := ref.Value
+	visiting[schema] = true
+	defer delete(visiting, schema)
+
+	types := schema.Type.Slice()
+	if schema.Nullable && len(types) > 0 && !slices.Contains(types, "null") {
+		types = append(slices.Clone(types), "null")
+	}
+	if len(types) == 1 {
+		result["type"] = types[0]
+	} else if len(types) > 1 {
+		result["type"] = types
+	}
+	if len(schema.Enum) > 0 {
+		enum := schema.Enum
+		if schema.Nullable && !slices.Contains(enum, nil) {
+			enum = append(slices.Clone(enum), nil)
+		}
+		result["enum"] = enum
+	}
+	if schema.Const != nil {
+		result["const"] = schema.Const
+	}
+
+	if len(schema.Properties) > 0 {
+		properties := make(map[string]any, len(schema.Properties))
+		for name, property := range schema.Properties {
+			// write-only properties are never part of a response
+			if property != nil && property.Value != nil && property.Value.WriteOnly {
+				continue
+			}
+			properties[name] = toJsonSchema(property, visiting)
+		}
+		result["properties"] = properties
+	}
+	var required []string
+	for _, name := range schema.Required {
+		if property := schema.Properties[name]; property == nil || property.Value == nil || !property.Value.WriteOnly {
+			required = append(required, name)
+		}
+	}
+	if len(required) > 0 {
+		result["required"] = required
+	}
+	if schema.AdditionalProperties.Has != nil && !*schema.AdditionalProperties.Has {
+		result["additionalProperties"] = false
+	} else if schema.AdditionalProperties.Schema != nil {
+		result["additionalProperties"] = toJsonSchema(schema.AdditionalProperties.Schema, visiting)
+	}
+	if schema.Items != nil {
+		result["items"] = toJsonSchema(schema.Items, visiting)
+	}
+
+	for keyword, refs := range map[string]openapi3.SchemaRefs{"allOf": schema.AllOf, "anyOf": schema.AnyOf, "oneOf": schema.OneOf} {
+		if len(refs) == 0 {
+			continue
+		}
+		schemas := make([]any, 0, len(refs))
+		for _, child := range refs {
+			schemas = append(schemas, toJsonSchema(child, visiting))
+		}
+		result[keyword] = schemas
+	}
+	if schema.Not != nil {
+		result["not"] = toJsonSchema(schema.Not, visiting)
+	}
+
+	if schema.Min != nil {
+		result["minimum"] = *schema.Min
+	}
+	if schema.Max != nil {
+		result["maximum"] = *schema.Max
+	}
+	if schema.MinLength > 0 {
+		result["minLength"] = schema.MinLength
+	}
+	if schema.MaxLength != nil {
+		result["maxLength"] = *schema.MaxLength
+	}
+	if schema.Pattern != "" {
+		result["pattern"] = schema.Pattern
+	}
+	if schema.MinItems > 0 {
+		result["minItems"] = schema.MinItems
+	}
+	if schema.MaxItems != nil {
+		result["maxItems"] = *schema.MaxItems
+	}
+	return result
+}
@@ -0,0 +1,164 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOpenApiSpecification = `openapi: 3.0.3
+info:
+  title: Petstore
+servers:
+  - url: "{scheme}://petstore.example.com/v1"
+    variables:
+      scheme:
+        default: https
+paths:
+  /pets:
+    get:
+      summary: List pets
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/Pet"
+        default:
+          description: error
+    post:
+      responses:
+        "201":
+          description: created
+  /pets/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        "200":
+          description: pet
+  /search:
+    get:
+      parameters:
+        - name: q
+          in: query
+          required: true
+          schema:
+            type: string
+      responses:
+        "200":
+          description: results
+  /health:
+    get:
+      operationId: health
+      responses:
+        2XX:
+          description: healthy
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id, name, password]
+      properties:
+        id:
+          type: integer
+        name:
+          type: string
+          nullable: true
+        password:
+          type: string
+          writeOnly: true
+        parent:
+          $ref: "#/components/schemas/Pet"
+`
+
+func TestGenerateOpenApiCollection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "petstore.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testOpenApiSpecification), 0600))
+
+	collection, err := generateOpenApiCollection(path)
+	require.NoError(t, err)
+
+	collectionPath := writeTestFile(t, string(mustMarshalJson(t, collection)))
+	assert.NoError(t, validateCollectionFile(collectionPath))
+	assert.Equal(t, "Petstore", collection["info"].(map[string]any)["name"])
+	assert.Equal(t, []any{map[string]any{"key": "baseUrl", "value": "https://petstore.example.com/v1", "type": "string"}}, collection["variable"])
+
+	items := collection["item"].([]any)
+	require.Len(t, items, 2)
+	health := items[0].(map[string]any)
+	assert.Equal(t, "health", health["name"])
+	assert.Equal(t, []string{
+		`pm.test("Status code is successful", function () {`,
+		`    pm.response.to.be.success;`,
+		`});`,
+	}, health["event"].([]any)[0].(map[string]any)["script"].(map[string]any)["exec"])
+
+	pets := items[1].(map[string]any)
+	assert.Equal(t, "List pets", pets["name"])
+	assert.Equal(t, "{{baseUrl}}/pets", pets["request"].(map[string]any)["url"].(map[string]any)["raw"])
+	exec := pets["event"].([]any)[0].(map[string]any)["script"].(map[string]any)["exec"].([]string)
+	require.Len(t, exec, 7)
+	assert.Equal(t, `    pm.response.to.have.status(200);`, exec[1])
+	assert.Equal(t, `var schema = {"items":{"properties":{"id":{"type":"integer"},"name":{"type":["string","null"]},"parent":{}},"required":["id","name"],"type":"object"},"type":"array"};`, exec[3])
+	assert.Equal(t, `    pm.response.to.have.jsonSchema(schema);`, exec[5])
+}
+
+func mustMarshalJson(t *testing.T, value any) []byte {
+	t.Helper()
+	content, err := json.Marshal(value)
+	require.NoError(t, err)
+	return content
+}
+
+func TestOpenApiSourceDiscoversSpecificationsFromUrls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/openapi.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"openapi":"3.0.3","info":{"title":"Inventory","version":"1"},"servers":[{"url":"/v2"}],"paths":{"/items":{"get":{"responses":{"200":{"description":"items"}}}}}}`))
+	}))
+	t.Cleanup(server.Close)
+	source := openApiSource{specifications: []string{server.URL + "/api/openapi.json", server.URL + "/missing.json"}}
+
+	targets, err := source.discoverCollections()
+	assert.ErrorContains(t, err, "failed to generate a collection of OpenAPI specification "+server.URL+"/missing.json")
+	require.Len(t, targets, 1)
+	assert.Equal(t, "Inventory", targets[0].Label)
+	assert.Equal(t, []string{"openapi"}, targets[0].Attributes["postman.collection.source"])
+	assert.Equal(t, []string{server.URL + "/api/openapi.json"}, targets[0].Attributes["postman.collection.specification"])
+
+	collectionPath := filepath.Join(t.TempDir(), "collection.json")
+	_, err = source.fetchCollection(context.Background(), targets[0].Attributes, collectionPath)
+	require.NoError(t, err)
+	collection, err := readJsonObject(collectionPath)
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/v2", collection["variable"].([]any)[0].(map[string]any)["value"])
+	assert.Equal(t, targets[0].Attributes["postman.collection.id"][0], collection["info"].(map[string]any)["_postman_id"])
+
+	_, err = source.fetchCollection(context.Background(), map[string][]string{"postman.collection.specification": {"/etc/passwd"}}, collectionPath)
+	assert.ErrorContains(t, err, "OpenAPI specification /etc/passwd is not configured")
+}
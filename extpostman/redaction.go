@@ -0,0 +1,235 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/extension-postman/v2/config"
+)
+
+const (
+	// resultFullFile is the complete newman json report including all headers and bodies. It is
+	// only written if response bodies are included and never attached as an artifact: it is the
+	// source of the values the redaction masks in the other reports.
+	resultFullFile = "result-full.json"
+
+	redactedValue = "***"
+	// minRedactedValueLength avoids masking every occurrence of trivial header and body values
+	// like "1" or "ok". Secrets are masked regardless of their length.
+	minRedactedValueLength = 4
+)
+
+// redactor masks the values of sensitive headers and body fields. It reads the actual values
+// from the full newman report and replaces each of their occurrences in the other reports, so
+// the masking works regardless of how a report format renders headers and bodies.
+type redactor struct {
+	headers   map[string]bool
+	bodyPaths []jsonPath
+}
+
+type newmanFullReport struct {
+	Run struct {
+		Executions []newmanExecution `json:"executions"`
+	} `json:"run"`
+}
+
+type newmanExecution struct {
+	Request *struct {
+		Header []newmanKeyValue `json:"header"`
+		Body   *struct {
+			Raw string `json:"raw"`
+		} `json:"body"`
+	} `json:"request"`
+	Response *struct {
+		Header []newmanKeyValue `json:"header"`
+		Stream *struct {
+			Data []int `json:"data"`
+		} `json:"stream"`
+	} `json:"response"`
+}
+
+type newmanKeyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func newRedactor(headers []string, bodyPaths []string) (*redactor, error) {
+	r := &redactor{headers: make(map[string]bool)}
+	for _, header := range headers {
+		if header = strings.TrimSpace(header); header != "" {
+			r.headers[strings.ToLower(header)] = true
+		}
+	}
+	for _, expression := range bodyPaths {
+		if strings.TrimSpace(expression) == "" {
+			continue
+		}
+		path, err := compileJsonPath(expression)
+		if err != nil {
+			return nil, err
+		}
+		r.bodyPaths = append(r.bodyPaths, path)
+	}
+	return r, nil
+}
+
+// newConfiguredRedactor creates the redactor for the headers and body fields configured via
+// RedactHeaders and RedactBodyFields.
+func newConfiguredRedactor() (*redactor, error) {
+	return newRedactor(config.Config.RedactHeaders, config.Config.RedactBodyFields)
+}
+
+// collectValues returns the values to redact from the full reports of all attempts of the run.
+func (r *redactor) collectValues(workDir string) ([]string, error) {
+	fullReports, err := filepath.Glob(filepath.Join(workDir, strings.TrimSuffix(resultFullFile, ".json")+"*.json"))
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]bool)
+	for _, fullReport := range fullReports {
+		content, err := os.ReadFile(fullReport)
+		if err != nil {
+			return nil, err
+		}
+		var report newmanFullReport
+		if err := json.Unmarshal(content, &report); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(fullReport), err)
+		}
+		for _, execution := range report.Run.Executions {
+			if execution.Request != nil {
+				r.collectHeaderValues(execution.Request.Header, values)
+				if execution.Request.Body != nil {
+					r.collectBodyValues([]byte(execution.Request.Body.Raw), values)
+				}
+			}
+			if execution.Response != nil {
+				r.collectHeaderValues(execution.Response.Header, values)
+				if execution.Response.Stream != nil {
+					body := make([]byte, len(execution.Response.Stream.Data))
+					for i, b := range execution.Response.Stream.Data {
+						body[i] = byte(b)
+					}
+					r.collectBodyValues(body, values)
+				}
+			}
+		}
+	}
+
+	result := make([]string, 0, len(values))
+	for value := range values {
+		result = append(result, value)
+	}
+	return result, nil
+}
+
+func (r *redactor) collectHeaderValues(headers []newmanKeyValue, values map[string]bool) {
+	for _, header := range headers {
+		if r.headers[strings.ToLower(header.Key)] {
+			addRedactedValue(header.Value, values)
+		}
+	}
+}
+
+func (r *redactor) collectBodyValues(body []byte, values map[string]bool) {
+	if len(r.bodyPaths) == 0 || len(bytes.TrimSpace(body)) == 0 {
+		return
+	}
+	var document any
+	if err := json.Unmarshal(body, &document); err != nil {
+		// not a JSON body, there are no fields to select
+		return
+	}
+	for _, path := range r.bodyPaths {
+		for _, value := range path.find(document) {
+			addRedactedLeaves(value, values)
+		}
+	}
+}
+
+// addRedactedLeaves adds the strings and numbers of the value. Objects and arrays are redacted
+// value by value, as reports render them in all kinds of formatting.
+func addRedactedLeaves(value any, values map[string]bool) {
+	switch typed := value.(type) {
+	case string:
+		addRedactedValue(typed, values)
+	case float64:
+		addRedactedValue(strconv.FormatFloat(typed, 'f', -1, 64), values)
+	case map[string]any:
+		for _, child := range typed {
+			addRedactedLeaves(child, values)
+		}
+	case []any:
+		for _, child := range typed {
+			addRedactedLeaves(child, values)
+		}
+	}
+}
+
+func addRedactedValue(value string, values map[string]bool) {
+	value = strings.TrimSpace(value)
+	if len(value) < minRedactedValueLength {
+		log.Debug().Msgf("Not redacting a value shorter than %d characters", minRedactedValueLength)
+		return
+	}
+	values[value] = true
+}
+
+// maskValues replaces all occurrences of the values in data, including their HTML- and
+// JSON-escaped forms, as the values are rendered escaped in the HTML and JSON reports.
+func maskValues(data []byte, values []string) []byte {
+	if len(values) == 0 {
+		return data
+	}
+	var variants []string
+	for _, value := range values {
+		variants = append(variants, value, html.EscapeString(value))
+		if encoded, err := json.Marshal(value); err == nil {
+			variants = append(variants, string(encoded[1:len(encoded)-1]))
+		}
+	}
+	// replace longer values first, so a value containing another one is masked as a whole
+	slices.SortFunc(variants, func(a, b string) int {
+		if len(a) != len(b) {
+			return len(b) - len(a)
+		}
+		return strings.Compare(a, b)
+	})
+	variants = slices.Compact(variants)
+
+	replacements := make([]string, 0, 2*len(variants))
+	for _, variant := range variants {
+		replacements = append(replacements, variant, redactedValue)
+	}
+	return []byte(strings.NewReplacer(replacements...).Replace(string(data)))
+}
+
+// redactOutputs masks the sensitive header values and body fields in the run outputs. Only runs
+// including response bodies are redacted, as only they record the full report.
+func redactOutputs(state *PostmanState, contents []outputContent) ([]outputContent, error) {
+	if !state.IncludeResponseBodies {
+		return contents, nil
+	}
+	r, err := newConfiguredRedactor()
+	if err != nil {
+		return nil, err
+	}
+	values, err := r.collectValues(state.WorkDir)
+	if err != nil {
+		return nil, err
+	}
+	for i := range contents {
+		contents[i].data = maskValues(contents[i].data, values)
+	}
+	return contents, nil
+}
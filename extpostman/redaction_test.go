@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJsonPathFind(t *testing.T) {
+	document := map[string]any{
+		"token": "abc",
+		"users": []any{
+			map[string]any{"name": "a", "password": "first"},
+			map[string]any{"name": "b", "password": "second"},
+		},
+		"nested": map[string]any{"password": "third"},
+	}
+
+	tests := []struct {
+		expression string
+		want       []any
+	}{
+		{"$.token", []any{"abc"}},
+		{"$['token']", []any{"abc"}},
+		{"$.users[1].name", []any{"b"}},
+		{"$.users[-1].name", []any{"b"}},
+		{"$.users[*].password", []any{"first", "second"}},
+		{"$.unknown", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			path, err := compileJsonPath(tt.expression)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, path.find(document))
+		})
+	}
+
+	path, err := compileJsonPath("$..password")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []any{"first", "second", "third"}, path.find(document))
+
+	_, err = compileJsonPath("token")
+	assert.Error(t, err)
+	_, err = compileJsonPath("$.users[x]")
+	assert.Error(t, err)
+}
+
+func TestRedactorMasksHeadersAndBodyFields(t *testing.T) {
+	workDir := t.TempDir()
+	fullReport := `{"run":{"executions":[{
+		"request":{"header":[{"key":"authorization","value":"Bearer s3cr3t"},{"key":"Accept","value":"application/json"}],"body":{"raw":"{\"password\":\"hunter22\"}"}},
+		"response":{"header":[{"key":"Set-Cookie","value":"session=xyz123"}],"stream":{"type":"Buffer","data":[123,34,116,111,107,101,110,34,58,34,97,60,98,62,99,100,34,125]}}
+	}]}}`
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, resultFullFile), []byte(fullReport), 0600))
+
+	r, err := newRedactor([]string{"Authorization", "Set-Cookie"}, []string{"$.password", "$.token"})
+	require.NoError(t, err)
+	values, err := r.collectValues(workDir)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Bearer s3cr3t", "session=xyz123", "hunter22", "a<b>cd"}, values)
+
+	masked := maskValues([]byte(`Authorization: Bearer s3cr3t <td>a&lt;b&gt;cd</td> {"password":"hunter22"} application/json`), values)
+	assert.Equal(t, `Authorization: *** <td>***</td> {"password":"***"} application/json`, string(masked))
+}
+
+func TestRedactorMasksEachValueOfNestedBodyFields(t *testing.T) {
+	workDir := t.TempDir()
+	body := `{"credentials":{"user":"admin@example.com","keys":["key-one-1234",{"secret":"nested-s3cr3t"}],"pin":987654,"active":true}}`
+	encoded, err := json.Marshal(body)
+	require.NoError(t, err)
+	fullReport := `{"run":{"executions":[{"request":{"body":{"raw":` + string(encoded) + `}}}]}}`
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, resultFullFile), []byte(fullReport), 0600))
+
+	r, err := newRedactor(nil, []string{"$.credentials"})
+	require.NoError(t, err)
+	values, err := r.collectValues(workDir)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"admin@example.com", "key-one-1234", "nested-s3cr3t", "987654"}, values)
+
+	// the report renders the body pretty-printed
+	masked := maskValues([]byte(`{
+  "credentials": {
+    "user": "admin@example.com",
+    "keys": [
+      "key-one-1234",
+      { "secret": "nested-s3cr3t" }
+    ],
+    "pin": 987654,
+    "active": true
+  }
+}`), values)
+	assert.Equal(t, `{
+  "credentials": {
+    "user": "***",
+    "keys": [
+      "***",
+      { "secret": "***" }
+    ],
+    "pin": ***,
+    "active": true
+  }
+}`, string(masked))
+}
+
+func TestRedactOutputsOnlyIfResponseBodiesAreIncluded(t *testing.T) {
+	workDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, resultFullFile), []byte(`{"run":{"executions":[{"request":{"header":[{"key":"Authorization","value":"Bearer s3cr3t"}]}}]}}`), 0600))
+	contents := func() []outputContent {
+		return []outputContent{{runOutput: runOutput{fileName: resultHtmlFile}, data: []byte("Bearer s3cr3t")}}
+	}
+
+	redacted, err := redactOutputs(&PostmanState{WorkDir: workDir}, contents())
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer s3cr3t", string(redacted[0].data))
+
+	redacted, err = redactOutputs(&PostmanState{WorkDir: workDir, IncludeResponseBodies: true}, contents())
+	require.NoError(t, err)
+	assert.Equal(t, redactedValue, string(redacted[0].data))
+}
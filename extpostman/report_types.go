@@ -1,10 +1,18 @@
 package extpostman
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
 type NewmanJsonReport struct {
 	Run Run `json:"Run"`
 }
 type Run struct {
-	Stats *Stats `json:"Stats"`
+	Stats    *Stats    `json:"Stats"`
+	Failures []Failure `json:"Failures"`
 }
 type Stats struct {
 	Requests   *Stat `json:"Requests"`
@@ -15,3 +23,91 @@ type Stat struct {
 	Pending int `json:"pending"`
 	Failed  int `json:"failed"`
 }
+type Failure struct {
+	Parent *FailureItem  `json:"Parent"`
+	Source *FailureItem  `json:"Source"`
+	Error  *FailureError `json:"Error"`
+}
+type FailureItem struct {
+	Id   string `json:"Id"`
+	Name string `json:"Name"`
+}
+type FailureError struct {
+	Message string `json:"Message"`
+	Test    string `json:"Test"`
+}
+
+// readNewmanReport parses the json-summary report at path. A missing report (e.g. newman was
+// killed by its timeout) is not an error and yields a nil report.
+func readNewmanReport(path string) (*NewmanJsonReport, error) {
+	byteValue, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var report NewmanJsonReport
+	if err := json.Unmarshal(byteValue, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// RequestName returns the name of the request the failure occurred in.
+func (f Failure) RequestName() string {
+	if f.Source != nil && f.Source.Name != "" {
+		return f.Source.Name
+	}
+	if f.Parent != nil {
+		return f.Parent.Name
+	}
+	return ""
+}
+
+// Description identifies the failure by request and failed test, so failures of different
+// attempts can be compared with each other.
+func (f Failure) Description() string {
+	var detail string
+	if f.Error != nil {
+		detail = f.Error.Test
+		if detail == "" {
+			detail = f.Error.Message
+		}
+	}
+	return fmt.Sprintf("%s: %s", f.RequestName(), detail)
+}
+
+// FailureDescriptions returns the distinct failure descriptions of the run in report order.
+func (r *NewmanJsonReport) FailureDescriptions() []string {
+	if r == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var descriptions []string
+	for _, failure := range r.Run.Failures {
+		description := failure.Description()
+		if !seen[description] {
+			seen[description] = true
+			descriptions = append(descriptions, description)
+		}
+	}
+	return descriptions
+}
+
+// FailedRequestNames returns the distinct names of all requests with at least one failure.
+func (r *NewmanJsonReport) FailedRequestNames() []string {
+	if r == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for _, failure := range r.Run.Failures {
+		name := failure.RequestName()
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
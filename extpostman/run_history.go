@@ -0,0 +1,310 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/extension-kit"
+	"github.com/steadybit/extension-kit/exthttp"
+	"github.com/steadybit/extension-postman/v2/config"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	runVerdictSuccess = "success"
+	runVerdictFailed  = "failed"
+	runVerdictErrored = "errored"
+	runVerdictStopped = "stopped"
+
+	maxTopFailures = 10
+)
+
+var (
+	// runsBucket holds the run summaries keyed by end time and id, so iterating it yields the
+	// runs in chronological order. runIdsBucket maps a run id to its key in runsBucket.
+	runsBucket   = []byte("runs")
+	runIdsBucket = []byte("run-ids")
+
+	runHistory *runHistoryStore
+)
+
+// RunSummary is what remains of a collection run once Stop removed its working directory.
+type RunSummary struct {
+	Id             string    `json:"id"`
+	CollectionId   string    `json:"collectionId"`
+	CollectionName string    `json:"collectionName,omitempty"`
+	Environment    string    `json:"environment,omitempty"`
+	StartedAt      time.Time `json:"startedAt"`
+	EndedAt        time.Time `json:"endedAt"`
+	Verdict        string    `json:"verdict"`
+	ExitCode       int       `json:"exitCode"`
+	Attempts       int       `json:"attempts"`
+	Stats          *Stats    `json:"stats,omitempty"`
+	TopFailures    []string  `json:"topFailures,omitempty"`
+	// Environments holds the runs of an environment matrix per environment.
+	Environments []RunSummary `json:"environments,omitempty"`
+}
+
+type runHistoryStore struct {
+	db         *bolt.DB
+	maxEntries int
+}
+
+// InitRunHistory opens the run-history store configured via RunHistoryPath. The history is
+// optional: if it is disabled or cannot be opened, runs are simply not recorded.
+func InitRunHistory() {
+	if config.Config.RunHistoryPath == "" || config.Config.RunHistorySize <= 0 {
+		log.Info().Msg("Run history is disabled.")
+		return
+	}
+	store, err := openRunHistoryStore(config.Config.RunHistoryPath, config.Config.RunHistorySize)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to open run history at %s, runs will not be recorded.", config.Config.RunHistoryPath)
+		return
+	}
+	runHistory = store
+}
+
+func openRunHistoryStore(path string, maxEntries int) (*runHistoryStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(runsBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(runIdsBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &runHistoryStore{db: db, maxEntries: maxEntries}, nil
+}
+
+func (s *runHistoryStore) close() error {
+	return s.db.Close()
+}
+
+// add stores the summary and drops the oldest runs beyond the configured number of entries.
+func (s *runHistoryStore) add(summary RunSummary) error {
+	value, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	key := make([]byte, 8, 8+len(summary.Id))
+	binary.BigEndian.PutUint64(key, uint64(summary.EndedAt.UnixNano()))
+	key = append(key, summary.Id...)
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		runs := tx.Bucket(runsBucket)
+		ids := tx.Bucket(runIdsBucket)
+		if previousKey := ids.Get([]byte(summary.Id)); previousKey != nil {
+			if err := runs.Delete(previousKey); err != nil {
+				return err
+			}
+		}
+		if err := runs.Put(key, value); err != nil {
+			return err
+		}
+		if err := ids.Put([]byte(summary.Id), key); err != nil {
+			return err
+		}
+
+		// Bucket.Stats does not reflect the pending writes of this transaction, so count via cursor
+		count := 0
+		cursor := runs.Cursor()
+		for k, _ := cursor.First(); k != nil; k, _ = cursor.Next() {
+			count++
+		}
+		var expired [][]byte
+		for k, v := cursor.First(); k != nil && count-len(expired) > s.maxEntries; k, v = cursor.Next() {
+			var oldest RunSummary
+			if err := json.Unmarshal(v, &oldest); err == nil {
+				if err := ids.Delete([]byte(oldest.Id)); err != nil {
+					return err
+				}
+			}
+			expired = append(expired, append([]byte{}, k...))
+		}
+		for _, k := range expired {
+			if err := runs.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// list returns all recorded runs, the most recent first.
+func (s *runHistoryStore) list() ([]RunSummary, error) {
+	summaries := make([]RunSummary, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(runsBucket).Cursor()
+		for k, v := cursor.Last(); k != nil; k, v = cursor.Prev() {
+			var summary RunSummary
+			if err := json.Unmarshal(v, &summary); err != nil {
+				return err
+			}
+			summaries = append(summaries, summary)
+		}
+		return nil
+	})
+	return summaries, err
+}
+
+// get returns the run with the given id or nil if it is unknown.
+func (s *runHistoryStore) get(id string) (*RunSummary, error) {
+	var summary *RunSummary
+	err := s.db.View(func(tx *bolt.Tx) error {
+		key := tx.Bucket(runIdsBucket).Get([]byte(id))
+		if key == nil {
+			return nil
+		}
+		value := tx.Bucket(runsBucket).Get(key)
+		if value == nil {
+			return nil
+		}
+		summary = &RunSummary{}
+		return json.Unmarshal(value, summary)
+	})
+	return summary, err
+}
+
+// recordRun adds the summary of a finished step to the metrics and to the history, if the
+// history is enabled.
+func recordRun(summary RunSummary) {
+	observeRun(summary)
+	if runHistory == nil || summary.Id == "" {
+		return
+	}
+	if err := runHistory.add(summary); err != nil {
+		log.Warn().Err(err).Msgf("Failed to record run %s in the run history.", summary.Id)
+	}
+}
+
+// summarizeRun summarizes the finished newman run of the state, with its failures masked.
+func summarizeRun(state *PostmanState, exitCode int, report *NewmanJsonReport) RunSummary {
+	summary := newRunSummary(state, exitCode, report, time.Now())
+	failures, err := maskRunFailures(state, summary.TopFailures)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Failed to mask the failures of run %s, recording it without failures.", state.RunId)
+	}
+	summary.TopFailures = failures
+	return summary
+}
+
+// maskRunFailures redacts and masks the failures like the messages and artifacts of the run, as
+// assertion messages may echo header values or tokens and the history serves them as well.
+func maskRunFailures(state *PostmanState, failures []string) ([]string, error) {
+	if len(failures) == 0 {
+		return failures, nil
+	}
+	contents := make([]outputContent, len(failures))
+	for i, failure := range failures {
+		contents[i].data = []byte(failure)
+	}
+	contents, err := redactOutputs(state, contents)
+	if err != nil {
+		return nil, err
+	}
+	contents, err = maskSecretOutputs(state, contents)
+	if err != nil {
+		return nil, err
+	}
+	masked := make([]string, len(contents))
+	for i, content := range contents {
+		masked[i] = string(content.data)
+	}
+	return masked, nil
+}
+
+func newRunSummary(state *PostmanState, exitCode int, report *NewmanJsonReport, endedAt time.Time) RunSummary {
+	summary := RunSummary{
+		Id:             state.RunId,
+		CollectionId:   state.CollectionId,
+		CollectionName: state.CollectionName,
+		Environment:    state.EnvironmentIdOrName,
+		EndedAt:        endedAt,
+		ExitCode:       exitCode,
+		Attempts:       state.Attempt,
+	}
+	if state.StartedAt != nil {
+		summary.StartedAt = *state.StartedAt
+	}
+
+	switch {
+	case exitCode == 0:
+		summary.Verdict = runVerdictSuccess
+	case exitCode == -1:
+		summary.Verdict = runVerdictStopped
+	case report != nil && report.Run.Stats != nil &&
+		(report.Run.Stats.Assertions != nil && report.Run.Stats.Assertions.Failed > 0 ||
+			report.Run.Stats.Requests != nil && report.Run.Stats.Requests.Failed > 0):
+		summary.Verdict = runVerdictFailed
+	default:
+		summary.Verdict = runVerdictErrored
+	}
+
+	if report != nil {
+		summary.Stats = report.Run.Stats
+		failures := report.FailureDescriptions()
+		summary.TopFailures = failures[:min(len(failures), maxTopFailures)]
+	}
+	return summary
+}
+
+// RegisterRunHistoryHandlers exposes the run history via GET /postman/runs and
+// GET /postman/runs/{id}.
+func RegisterRunHistoryHandlers() {
+	exthttp.RegisterHttpHandler("GET /postman/runs", getRunHistory)
+	exthttp.RegisterHttpHandler("GET /postman/runs/{id}", getRunHistoryEntry)
+}
+
+func getRunHistory(w http.ResponseWriter, _ *http.Request, _ []byte) {
+	if runHistory == nil {
+		exthttp.WriteBody(w, []RunSummary{})
+		return
+	}
+	summaries, err := runHistory.list()
+	if err != nil {
+		exthttp.WriteError(w, extension_kit.ToError("Failed to read run history.", err))
+		return
+	}
+	exthttp.WriteBody(w, summaries)
+}
+
+func getRunHistoryEntry(w http.ResponseWriter, r *http.Request, _ []byte) {
+	id := r.PathValue("id")
+	var summary *RunSummary
+	if runHistory != nil {
+		var err error
+		summary, err = runHistory.get(id)
+		if err != nil {
+			exthttp.WriteError(w, extension_kit.ToError("Failed to read run history.", err))
+			return
+		}
+	}
+	if summary == nil {
+		writeNotFound(w, extension_kit.ToError("Run not found.", fmt.Errorf("no run with id %s in the run history", id)))
+		return
+	}
+	exthttp.WriteBody(w, summary)
+}
+
+func writeNotFound(w http.ResponseWriter, err extension_kit.ExtensionError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	if encodeErr := json.NewEncoder(w).Encode(err); encodeErr != nil {
+		log.Err(encodeErr).Msgf("Failed to write ExtensionError as response body")
+	}
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunHistoryKeepsMostRecentRuns(t *testing.T) {
+	store, err := openRunHistoryStore(filepath.Join(t.TempDir(), "runs.db"), 2)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.close() })
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, store.add(RunSummary{Id: fmt.Sprintf("run-%d", i), EndedAt: start.Add(time.Duration(i) * time.Minute)}))
+	}
+
+	summaries, err := store.list()
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	assert.Equal(t, "run-3", summaries[0].Id)
+	assert.Equal(t, "run-2", summaries[1].Id)
+
+	evicted, err := store.get("run-1")
+	require.NoError(t, err)
+	assert.Nil(t, evicted)
+}
+
+func TestRunHistoryHandlers(t *testing.T) {
+	store, err := openRunHistoryStore(filepath.Join(t.TempDir(), "runs.db"), 10)
+	require.NoError(t, err)
+	runHistory = store
+	t.Cleanup(func() {
+		runHistory = nil
+		_ = store.close()
+	})
+
+	state := &PostmanState{RunId: "4711", CollectionId: "645797", CollectionName: "shop", Attempt: 1}
+	report := &NewmanJsonReport{Run: Run{
+		Stats:    &Stats{Assertions: &Stat{Total: 2, Failed: 1}},
+		Failures: []Failure{{Source: &FailureItem{Name: "Get products"}, Error: &FailureError{Test: "Status code is 200"}}},
+	}}
+	recordRun(summarizeRun(state, 1, report))
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /postman/runs/{id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		getRunHistoryEntry(w, r, nil)
+	}))
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/postman/runs/4711", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var summary RunSummary
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &summary))
+	assert.Equal(t, "shop", summary.CollectionName)
+	assert.Equal(t, runVerdictFailed, summary.Verdict)
+	assert.Equal(t, []string{"Get products: Status code is 200"}, summary.TopFailures)
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/postman/runs/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestRunHistoryMasksSecretsInFailures(t *testing.T) {
+	store, err := openRunHistoryStore(filepath.Join(t.TempDir(), "runs.db"), 10)
+	require.NoError(t, err)
+	runHistory = store
+	t.Cleanup(func() {
+		runHistory = nil
+		_ = store.close()
+	})
+	workDir := t.TempDir()
+	require.NoError(t, addSecretEnvironmentValues(filepath.Join(workDir, environmentFile), []map[string]string{{"key": "token", "value": "s3cr3t-token"}}))
+
+	state := &PostmanState{RunId: "4712", WorkDir: workDir, Attempt: 1}
+	report := &NewmanJsonReport{Run: Run{
+		Failures: []Failure{{Source: &FailureItem{Name: "Get products"}, Error: &FailureError{Message: "expected header Authorization to be 'Bearer s3cr3t-token'"}}},
+	}}
+	recordRun(summarizeRun(state, 1, report))
+
+	recorder := httptest.NewRecorder()
+	getRunHistory(recorder, httptest.NewRequest(http.MethodGet, "/postman/runs", nil), nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "s3cr3t-token")
+	var summaries []RunSummary
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &summaries))
+	require.Len(t, summaries, 1)
+	assert.Equal(t, []string{"Get products: expected header Authorization to be 'Bearer ***'"}, summaries[0].TopFailures)
+}
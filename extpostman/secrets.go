@@ -0,0 +1,233 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-kit/extutil"
+	"github.com/steadybit/extension-postman/v2/config"
+)
+
+const (
+	environmentFile = "environment.json"
+
+	postmanVariableTypeSecret = "secret"
+)
+
+// secretMasker replaces secrets in everything a run hands out to the platform: messages and
+// artifacts. The secrets are the values of the secret variables in the run's environment file
+// and whatever matches one of the configured SecretMaskPatterns.
+type secretMasker struct {
+	values   []string
+	patterns []*regexp.Regexp
+}
+
+// postmanEnvironmentFile is the format of environment files, as downloaded from the Postman API
+// and as read by newman.
+type postmanEnvironmentFile struct {
+	Id     string                    `json:"id,omitempty"`
+	Name   string                    `json:"name,omitempty"`
+	Values []postmanEnvironmentValue `json:"values"`
+}
+
+type postmanEnvironmentValue struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Enabled bool   `json:"enabled"`
+}
+
+// newSecretMasker creates the masker for the run with the given working directory.
+func newSecretMasker(workDir string) (*secretMasker, error) {
+	patterns, err := compileSecretMaskPatterns(config.Config.SecretMaskPatterns)
+	if err != nil {
+		return nil, err
+	}
+	values, err := readSecretEnvironmentValues(filepath.Join(workDir, environmentFile))
+	if err != nil {
+		return nil, err
+	}
+	return &secretMasker{values: values, patterns: patterns}, nil
+}
+
+func compileSecretMaskPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret mask pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// readSecretEnvironmentValues returns the values of the secret variables in the environment
+// file. A missing file (the run uses no environment) has no secrets. Unlike redacted values,
+// secrets are masked regardless of their length.
+func readSecretEnvironmentValues(path string) ([]string, error) {
+	variables, err := readSecretEnvironmentVariables(path)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]bool)
+	for _, variable := range variables {
+		if value := strings.TrimSpace(variable.Value); value != "" {
+			values[value] = true
+		}
+	}
+	result := make([]string, 0, len(values))
+	for value := range values {
+		result = append(result, value)
+	}
+	return result, nil
+}
+
+// getShortSecretsMessage warns about secret variables with values shorter than
+// minRedactedValueLength, as masking them also masks every other occurrence of their characters.
+func getShortSecretsMessage(path string) (*action_kit_api.Message, error) {
+	variables, err := readSecretEnvironmentVariables(path)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, variable := range variables {
+		if value := strings.TrimSpace(variable.Value); value != "" && len(value) < minRedactedValueLength {
+			keys = append(keys, variable.Key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	message := fmt.Sprintf("Secret variables shorter than %d characters: %s. Their values are masked wherever they occur in messages and artifacts, also as part of unrelated text.", minRedactedValueLength, strings.Join(keys, ", "))
+	log.Warn().Msg(message)
+	return &action_kit_api.Message{
+		Level:   extutil.Ptr(action_kit_api.Warn),
+		Message: message,
+	}, nil
+}
+
+func readSecretEnvironmentVariables(path string) ([]postmanEnvironmentValue, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var environment postmanEnvironmentFile
+	if err := json.Unmarshal(content, &environment); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	var variables []postmanEnvironmentValue
+	for _, value := range environment.Values {
+		if value.Type == postmanVariableTypeSecret {
+			variables = append(variables, value)
+		}
+	}
+	return variables, nil
+}
+
+// addSecretEnvironmentValues adds the variables as secrets to the environment file, creating it
+// if the run has no environment yet. Passing the secrets via the file keeps them out of the
+// newman command, which is part of the action state.
+func addSecretEnvironmentValues(path string, variables []map[string]string) error {
+	var environment postmanEnvironmentFile
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(content, &environment); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+		}
+	}
+	for _, variable := range variables {
+		environment.Values = append(environment.Values, postmanEnvironmentValue{
+			Key:     variable["key"],
+			Value:   variable["value"],
+			Type:    postmanVariableTypeSecret,
+			Enabled: true,
+		})
+	}
+	content, err = json.Marshal(environment)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0600)
+}
+
+// mask replaces the secret values, including their escaped forms, and the matches of the
+// patterns. If a pattern has a capture group, only the first group is replaced.
+func (m *secretMasker) mask(data []byte) []byte {
+	data = maskValues(data, m.values)
+	for _, pattern := range m.patterns {
+		if pattern.NumSubexp() == 0 {
+			data = pattern.ReplaceAll(data, []byte(redactedValue))
+			continue
+		}
+		data = pattern.ReplaceAllFunc(data, func(match []byte) []byte {
+			group := pattern.FindSubmatchIndex(match)
+			if len(group) < 4 || group[2] < 0 {
+				return match
+			}
+			masked := append([]byte{}, match[:group[2]]...)
+			masked = append(masked, redactedValue...)
+			return append(masked, match[group[3]:]...)
+		})
+	}
+	return data
+}
+
+func (m *secretMasker) maskString(value string) string {
+	return string(m.mask([]byte(value)))
+}
+
+// maskMessages masks the texts and fields of the messages.
+func (m *secretMasker) maskMessages(messages []action_kit_api.Message) []action_kit_api.Message {
+	for i := range messages {
+		messages[i].Message = m.maskString(messages[i].Message)
+		if messages[i].Fields != nil {
+			fields := make(action_kit_api.MessageFields, len(*messages[i].Fields))
+			for key, value := range *messages[i].Fields {
+				fields[key] = m.maskString(value)
+			}
+			messages[i].Fields = &fields
+		}
+	}
+	return messages
+}
+
+// maskSecretMessages masks the messages of the run before they are returned to the platform.
+func maskSecretMessages(state *PostmanState, messages []action_kit_api.Message) ([]action_kit_api.Message, error) {
+	masker, err := newSecretMasker(state.WorkDir)
+	if err != nil {
+		return nil, err
+	}
+	return masker.maskMessages(messages), nil
+}
+
+// maskSecretOutputs masks the run outputs before they are attached as artifacts.
+func maskSecretOutputs(state *PostmanState, contents []outputContent) ([]outputContent, error) {
+	masker, err := newSecretMasker(state.WorkDir)
+	if err != nil {
+		return nil, err
+	}
+	for i := range contents {
+		contents[i].data = masker.mask(contents[i].data)
+	}
+	return contents, nil
+}
@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/extension-kit/extutil"
+	"github.com/steadybit/extension-postman/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepareCollectionRunWithSecretEnvironment(t *testing.T) {
+	server := newPostmanApiStub(t)
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_API_KEY", "123456")
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_BASE_URL", server.URL)
+	config.ParseConfiguration()
+
+	// Given
+	requestBody := extutil.JsonMangle(action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{
+			"duration":            60000,
+			"environmentIdOrName": "5f757f0d-de24-462c-867f-256bb696d2dd",
+			"secretEnvironment": []map[string]string{
+				{"key": "token", "value": "s3cr3t-token"},
+			},
+		},
+		Target: &action_kit_api.Target{
+			Attributes: map[string][]string{
+				"postman.collection.id": {"645797"},
+			},
+		},
+	})
+	action := NewPostmanAction()
+	state := action.NewEmptyState()
+
+	// When
+	_, err := action.Prepare(context.TODO(), &state, requestBody)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(state.WorkDir) })
+
+	// Then the secret is added to the downloaded environment instead of the command
+	for _, arg := range state.Command {
+		assert.NotContains(t, arg, "s3cr3t-token")
+	}
+	assert.Contains(t, state.Command, filepath.Join(state.WorkDir, environmentFile))
+	values, err := readSecretEnvironmentValues(filepath.Join(state.WorkDir, environmentFile))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s3cr3t-token"}, values)
+}
+
+func TestShortSecretsAreMaskedWithWarning(t *testing.T) {
+	path := filepath.Join(t.TempDir(), environmentFile)
+	require.NoError(t, os.WriteFile(path, []byte(`{"values":[
+		{"key":"pin","value":"42","type":"secret","enabled":true},
+		{"key":"token","value":"s3cr3t-token","type":"secret","enabled":true},
+		{"key":"port","value":"80","type":"default","enabled":true}
+	]}`), 0600))
+
+	values, err := readSecretEnvironmentValues(path)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"42", "s3cr3t-token"}, values)
+	assert.Equal(t, "pin *** on port 80", (&secretMasker{values: values}).maskString("pin 42 on port 80"))
+
+	message, err := getShortSecretsMessage(path)
+	require.NoError(t, err)
+	require.NotNil(t, message)
+	assert.Equal(t, action_kit_api.Warn, *message.Level)
+	assert.Contains(t, message.Message, "Secret variables shorter than 4 characters: pin.")
+}
+
+func TestPrepareCollectionRunWithInvalidSecretMaskPattern(t *testing.T) {
+	server := newPostmanApiStub(t)
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_API_KEY", "123456")
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_BASE_URL", server.URL)
+	t.Setenv("STEADYBIT_EXTENSION_SECRET_MASK_PATTERNS", "token=(\\S+")
+	config.ParseConfiguration()
+	t.Cleanup(func() { config.Config.SecretMaskPatterns = nil })
+
+	requestBody := extutil.JsonMangle(action_kit_api.PrepareActionRequestBody{
+		Config: map[string]any{"duration": 60000},
+		Target: &action_kit_api.Target{
+			Attributes: map[string][]string{
+				"postman.collection.id": {"645797"},
+			},
+		},
+	})
+	action := NewPostmanAction()
+	state := action.NewEmptyState()
+
+	_, err := action.Prepare(context.TODO(), &state, requestBody)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid secret mask configuration.")
+}
+
+func TestSecretMaskerMasksValuesAndPatterns(t *testing.T) {
+	workDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, environmentFile), []byte(`{"values":[
+		{"key":"password","value":"p\"ssw0rd","type":"secret","enabled":true},
+		{"key":"host","value":"example.com","type":"default","enabled":true}
+	]}`), 0600))
+	patterns, err := compileSecretMaskPatterns([]string{`token=(\w+)`, `sk_live_\w+`})
+	require.NoError(t, err)
+	values, err := readSecretEnvironmentValues(filepath.Join(workDir, environmentFile))
+	require.NoError(t, err)
+	masker := &secretMasker{values: values, patterns: patterns}
+
+	assert.Equal(t, "GET https://example.com?token=*** with p***", masker.maskString(`GET https://example.com?token=abc123 with p***`))
+	assert.Equal(t, `login *** {"password":"***"} key ***`, masker.maskString(`login p"ssw0rd {"password":"p\"ssw0rd"} key sk_live_4242`))
+
+	messages := masker.maskMessages([]action_kit_api.Message{{
+		Message: `using p"ssw0rd`,
+		Fields:  new(action_kit_api.MessageFields{"url": "https://example.com?token=abc123"}),
+	}})
+	assert.Equal(t, "using ***", messages[0].Message)
+	assert.Equal(t, "https://example.com?token=***", (*messages[0].Fields)["url"])
+}
+
+func TestGetArtifactsMasksSecrets(t *testing.T) {
+	config.Config.MaxArtifactSize = 0
+	workDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, environmentFile), []byte(`{"values":[{"key":"token","value":"s3cr3t-token","type":"secret","enabled":true}]}`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, resultSummaryFile), []byte(`{"url":"https://example.com?token=s3cr3t-token"}`), 0600))
+
+	artifacts, _, err := getArtifacts(&PostmanState{WorkDir: workDir, Attempt: 1, ArtifactFormat: artifactFormatFiles})
+
+	require.NoError(t, err)
+	require.Len(t, artifacts, 1)
+	assert.Equal(t, "eyJ1cmwiOiJodHRwczovL2V4YW1wbGUuY29tP3Rva2VuPSoqKiJ9", artifacts[0].Data)
+}
@@ -0,0 +1,312 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/steadybit/action-kit/go/action_kit_api/v2"
+	"github.com/steadybit/discovery-kit/go/discovery_kit_api"
+	"github.com/steadybit/extension-postman/v2/config"
+)
+
+const (
+	sourceApi  = "api"
+	sourceFile = "file"
+
+	attributeCollectionId     = "postman.collection.id"
+	attributeCollectionName   = "postman.collection.name"
+	attributeCollectionSource = "postman.collection.source"
+	attributeCollectionPath   = "postman.collection.path"
+
+	attributeCollectionUid       = "postman.collection.uid"
+	attributeCollectionOwner     = "postman.collection.owner"
+	attributeCollectionCreatedAt = "postman.collection.created-at"
+	attributeCollectionUpdatedAt = "postman.collection.updated-at"
+	attributeCollectionIsPublic  = "postman.collection.is-public"
+	attributeCollectionIsFork    = "postman.collection.is-fork"
+	attributeCollectionForkLabel = "postman.collection.fork.label"
+	attributeCollectionForkFrom  = "postman.collection.fork.from"
+)
+
+// collectionSource is where collections and their environments come from. Discovery emits the
+// collections of all configured sources, and a run fetches its collection from the source
+// named by the target's postman.collection.source attribute.
+type collectionSource interface {
+	// name is the value of the postman.collection.source attribute of the source's targets.
+	name() string
+	discoverCollections() ([]discovery_kit_api.Target, error)
+	// fetchCollection writes the collection of the target to destPath. Cancelling the context
+	// aborts pending downloads.
+	fetchCollection(ctx context.Context, attributes map[string][]string, destPath string) ([]action_kit_api.Message, error)
+	// fetchEnvironment writes the environment with the given id or name to destPath.
+	fetchEnvironment(ctx context.Context, attributes map[string][]string, environmentIdOrName, destPath string) ([]action_kit_api.Message, error)
+}
+
+// getCollectionSources returns the sources enabled by the configuration. The Postman API is
+// used with each configured account.
+func getCollectionSources() []collectionSource {
+	var sources []collectionSource
+	for _, account := range getPostmanAccounts() {
+		sources = append(sources, apiSource{account: account})
+	}
+	if config.Config.CollectionsDir != "" {
+		sources = append(sources, fileSource{dir: config.Config.CollectionsDir})
+	}
+	if config.Config.GitRepositoryUrl != "" {
+		sources = append(sources, gitSource{
+			repository: config.Config.GitRepositoryUrl,
+			branch:     config.Config.GitBranch,
+			paths:      config.Config.GitCollectionPaths,
+			dir:        config.Config.GitCloneDir,
+		})
+	}
+	if len(config.Config.CollectionUrls) > 0 {
+		sources = append(sources, httpSource{
+			collectionUrls:  config.Config.CollectionUrls,
+			environmentUrls: config.Config.EnvironmentUrls,
+			bearerToken:     config.Config.HttpSourceBearerToken,
+			username:        config.Config.HttpSourceUsername,
+			password:        config.Config.HttpSourcePassword,
+		})
+	}
+	if len(config.Config.OpenApiSpecifications) > 0 {
+		sources = append(sources, openApiSource{specifications: config.Config.OpenApiSpecifications})
+	}
+	return sources
+}
+
+// getCollectionSource returns the source of the target. Targets without a source attribute
+// were discovered via the Postman API, which is accessed with the account of the target.
+func getCollectionSource(attributes map[string][]string) (collectionSource, error) {
+	name := sourceApi
+	if values := attributes[attributeCollectionSource]; len(values) > 0 {
+		name = values[0]
+	}
+	if name == sourceApi && len(getPostmanAccounts()) > 0 {
+		account, err := getPostmanAccount(attributes)
+		if err != nil {
+			return nil, err
+		}
+		return apiSource{account: account}, nil
+	}
+	for _, source := range getCollectionSources() {
+		if source.name() == name {
+			return source, nil
+		}
+	}
+	return nil, fmt.Errorf("collection source %q is not configured", name)
+}
+
+// discoverAllCollections collects the targets of all sources. A failing source keeps the targets
+// it discovered last and does not hide the collections of the others. The errors of failing
+// sources without previous targets are only returned if no source discovered any target, so the
+// cached discovery keeps its last good target set instead.
+func discoverAllCollections() ([]discovery_kit_api.Target, error) {
+	targets := make([]discovery_kit_api.Target, 0)
+	var errs []error
+	for _, source := range getCollectionSources() {
+		started := time.Now()
+		sourceTargets, discoveryErr := source.discoverCollections()
+		sourceTargets, err := lastDiscoveredCollections.update(discoveryKey(source), sourceTargets, discoveryErr)
+		observeDiscovery("collections", discoveryKey(source), started, sourceTargets, discoveryErr)
+		if err != nil {
+			log.Error().Msgf("Failed to discover collections of source %s: %s", source.name(), err)
+			errs = append(errs, fmt.Errorf("failed to discover collections of source %s: %w", source.name(), err))
+			continue
+		}
+		targets = append(targets, sourceTargets...)
+	}
+	if len(targets) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return targets, nil
+}
+
+// discoveryKey tells the sources apart, including the Postman API accessed with different accounts.
+func discoveryKey(source collectionSource) string {
+	if api, ok := source.(apiSource); ok {
+		return sourceApi + "/" + api.account.name
+	}
+	return source.name()
+}
+
+func singleAttribute(attributes map[string][]string, attribute string) (string, error) {
+	values := attributes[attribute]
+	if len(values) == 0 {
+		return "", fmt.Errorf("target has no attribute %s", attribute)
+	}
+	if len(values) > 1 {
+		return "", fmt.Errorf("target has more than one value for attribute %s", attribute)
+	}
+	return values[0], nil
+}
+
+// apiSource provides the collections and environments of the Postman API accessible with the
+// account.
+type apiSource struct {
+	account postmanAccount
+}
+
+func (s apiSource) name() string {
+	return sourceApi
+}
+
+// discoverCollections discovers the collections per workspace, so every target carries the
+// workspaces it is part of. Only the configured workspaces are discovered, if any.
+func (s apiSource) discoverCollections() ([]discovery_kit_api.Target, error) {
+	workspaces, err := getPostmanWorkspaces(s.account)
+	if err != nil {
+		if len(config.Config.PostmanWorkspaces) > 0 {
+			return nil, fmt.Errorf("failed to get workspaces: %w", err)
+		}
+		log.Warn().Msgf("Failed to get workspaces of account %s, discovering collections without workspace attributes: %s", s.account.name, err)
+		collections, err := GetPostmanCollections(s.account)
+		if err != nil {
+			return nil, err
+		}
+		refreshCachedCollections(s.account, collections)
+		environments, err := GetPostmanEnvironments(s.account)
+		if err != nil {
+			return nil, err
+		}
+		refreshCachedEnvironments(s.account, environments)
+		targets := make([]discovery_kit_api.Target, len(collections))
+		summarized := make([]*discovery_kit_api.Target, len(collections))
+		for i, collection := range collections {
+			targets[i] = newApiCollectionTarget(s.account, collection)
+			addEnvironmentAttributes(&targets[i], environments)
+			summarized[i] = &targets[i]
+		}
+		addApiCollectionSummaries(s.account, collections, summarized)
+		return targets, nil
+	}
+
+	var collections []PostmanCollection
+	var allEnvironments []PostmanEnvironment
+	targets := make(map[string]*discovery_kit_api.Target)
+	for _, workspace := range filterWorkspaces(workspaces, config.Config.PostmanWorkspaces) {
+		workspaceCollections, err := getPostmanCollections(s.account, workspace.Id)
+		if err != nil {
+			return nil, err
+		}
+		environments, err := getPostmanEnvironments(context.Background(), s.account, workspace.Id)
+		if err != nil {
+			return nil, err
+		}
+		for _, environment := range environments {
+			if !slices.ContainsFunc(allEnvironments, func(e PostmanEnvironment) bool { return e.Id == environment.Id }) {
+				allEnvironments = append(allEnvironments, environment)
+			}
+		}
+		for _, collection := range workspaceCollections {
+			target, ok := targets[collection.Id]
+			if !ok {
+				collections = append(collections, collection)
+				target = new(newApiCollectionTarget(s.account, collection))
+				targets[collection.Id] = target
+			}
+			target.Attributes[attributeWorkspaceId] = append(target.Attributes[attributeWorkspaceId], workspace.Id)
+			target.Attributes[attributeWorkspaceName] = append(target.Attributes[attributeWorkspaceName], workspace.Name)
+			if !slices.Contains(target.Attributes[attributeWorkspaceType], workspace.Type) {
+				target.Attributes[attributeWorkspaceType] = append(target.Attributes[attributeWorkspaceType], workspace.Type)
+			}
+			addEnvironmentAttributes(target, environments)
+		}
+	}
+	refreshCachedCollections(s.account, collections)
+	refreshCachedEnvironments(s.account, allEnvironments)
+
+	summarized := make([]*discovery_kit_api.Target, len(collections))
+	for i, collection := range collections {
+		summarized[i] = targets[collection.Id]
+	}
+	addApiCollectionSummaries(s.account, collections, summarized)
+
+	result := make([]discovery_kit_api.Target, 0, len(collections))
+	for _, collection := range collections {
+		result = append(result, *targets[collection.Id])
+	}
+	return result, nil
+}
+
+// newApiCollectionTarget creates the target of the collection with the metadata of the Postman
+// API.
+func newApiCollectionTarget(account postmanAccount, collection PostmanCollection) discovery_kit_api.Target {
+	target := discovery_kit_api.Target{
+		Id:         accountTargetId(account, collection.Id),
+		TargetType: targetID,
+		Label:      collection.Name,
+		Attributes: map[string][]string{
+			attributeCollectionId:       {collection.Id},
+			attributeCollectionName:     {collection.Name},
+			attributeCollectionSource:   {sourceApi},
+			attributeAccount:            {account.name},
+			attributeCollectionIsPublic: {strconv.FormatBool(collection.IsPublic)},
+			attributeCollectionIsFork:   {strconv.FormatBool(collection.Fork != nil)},
+		},
+	}
+	optionalAttributes := map[string]string{
+		attributeCollectionUid:       collection.Uid,
+		attributeCollectionOwner:     collection.Owner,
+		attributeCollectionCreatedAt: collection.CreatedAt,
+		attributeCollectionUpdatedAt: collection.UpdatedAt,
+	}
+	if collection.Fork != nil {
+		optionalAttributes[attributeCollectionForkLabel] = collection.Fork.Label
+		optionalAttributes[attributeCollectionForkFrom] = collection.Fork.From
+	}
+	for attribute, value := range optionalAttributes {
+		if value != "" {
+			target.Attributes[attribute] = []string{value}
+		}
+	}
+	return target
+}
+
+// addEnvironmentAttributes adds the environments the collection can be run in, which the action
+// offers as options of its environment parameters.
+func addEnvironmentAttributes(target *discovery_kit_api.Target, environments []PostmanEnvironment) {
+	for _, environment := range environments {
+		if slices.Contains(target.Attributes[attributeEnvironmentId], environment.Id) {
+			continue
+		}
+		target.Attributes[attributeEnvironmentId] = append(target.Attributes[attributeEnvironmentId], environment.Id)
+		if !slices.Contains(target.Attributes[attributeEnvironmentName], environment.Name) {
+			target.Attributes[attributeEnvironmentName] = append(target.Attributes[attributeEnvironmentName], environment.Name)
+		}
+	}
+}
+
+func (s apiSource) fetchCollection(ctx context.Context, attributes map[string][]string, destPath string) ([]action_kit_api.Message, error) {
+	collectionId, err := singleAttribute(attributes, attributeCollectionId)
+	if err != nil {
+		return nil, err
+	}
+	message, err := DownloadCollection(ctx, s.account, collectionId, destPath)
+	return optionalMessage(message), err
+}
+
+// fetchEnvironment looks up environments by name within the workspaces of the collection.
+func (s apiSource) fetchEnvironment(ctx context.Context, attributes map[string][]string, environmentIdOrName, destPath string) ([]action_kit_api.Message, error) {
+	environmentId, err := GetPostEnvironmentId(ctx, s.account, environmentIdOrName, attributes[attributeWorkspaceId]...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get environment id: %w", err)
+	}
+	message, err := DownloadEnvironment(ctx, s.account, environmentId, destPath)
+	return optionalMessage(message), err
+}
+
+func optionalMessage(message *action_kit_api.Message) []action_kit_api.Message {
+	if message == nil {
+		return nil
+	}
+	return []action_kit_api.Message{*message}
+}
@@ -0,0 +1,289 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+const maxReportedProblems = 10
+
+var (
+	//go:embed schemas/collection-v2.0.0.json
+	collectionSchemaV200 []byte
+	//go:embed schemas/collection-v2.1.0.json
+	collectionSchemaV210 []byte
+
+	// collectionSchemas are the published JSON schemas of the Postman Collection format versions
+	// newman can run, by version.
+	collectionSchemas = map[string][]byte{
+		"v2.0.0": collectionSchemaV200,
+		"v2.1.0": collectionSchemaV210,
+	}
+	compiledCollectionSchemas = sync.OnceValues(compileCollectionSchemas)
+
+	schemaMessagePrinter = message.NewPrinter(language.English)
+)
+
+// validationProblems collects the problems of a file with the JSON path they were found at.
+type validationProblems []string
+
+func (p *validationProblems) add(path string, format string, args ...any) {
+	*p = append(*p, fmt.Sprintf("%s: %s", path, fmt.Sprintf(format, args...)))
+}
+
+func (p validationProblems) err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	problems := p[:min(len(p), maxReportedProblems)]
+	message := strings.Join(problems, "; ")
+	if len(p) > len(problems) {
+		message += fmt.Sprintf(" and %d more", len(p)-len(problems))
+	}
+	return errors.New(message)
+}
+
+func compileCollectionSchemas() (map[string]*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	urls := make(map[string]string, len(collectionSchemas))
+	for version, content := range collectionSchemas {
+		document, err := jsonschema.UnmarshalJSON(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("invalid collection schema %s: %w", version, err)
+		}
+		urls[version] = fmt.Sprintf("https://schema.getpostman.com/json/collection/%s/collection.json", version)
+		if err := compiler.AddResource(urls[version], document); err != nil {
+			return nil, fmt.Errorf("invalid collection schema %s: %w", version, err)
+		}
+	}
+	schemas := make(map[string]*jsonschema.Schema, len(urls))
+	for version, url := range urls {
+		schema, err := compiler.Compile(url)
+		if err != nil {
+			return nil, fmt.Errorf("invalid collection schema %s: %w", version, err)
+		}
+		schemas[version] = schema
+	}
+	return schemas, nil
+}
+
+// validateCollectionFile validates the collection against the JSON schema of its Postman
+// Collection format version, v2.0 or v2.1, and checks that it has enabled requests with urls, so
+// unsupported or broken collections are rejected before newman runs.
+func validateCollectionFile(path string) error {
+	collection, err := readJsonObject(path)
+	if err != nil {
+		return err
+	}
+
+	var problems validationProblems
+	info, _ := collection["info"].(map[string]any)
+	schemaUrl, _ := info["schema"].(string)
+	version := collectionSchemaVersion(schemaUrl)
+	if version == "" {
+		problems.add("$.info.schema", "unsupported collection format %q, expected Postman Collection %s", schemaUrl, strings.Join(slices.Sorted(maps.Keys(collectionSchemas)), " or "))
+		return problems.err()
+	}
+	schemas, err := compiledCollectionSchemas()
+	if err != nil {
+		return err
+	}
+	if err := schemas[version].Validate(map[string]any(collection)); err != nil {
+		var validationErr *jsonschema.ValidationError
+		if !errors.As(err, &validationErr) {
+			return err
+		}
+		addSchemaProblems(validationErr, &problems)
+		slices.Sort(problems)
+		return slices.Compact(problems).err()
+	}
+
+	if name, _ := info["name"].(string); strings.TrimSpace(name) == "" {
+		problems.add("$.info.name", "missing collection name")
+	}
+	items, _ := collection["item"].([]any)
+	requests, disabled := validateItems(items, "$.item", &problems)
+	if len(problems) == 0 && requests == 0 {
+		if disabled > 0 {
+			problems.add("$.item", "collection contains no enabled requests, %d items are disabled", disabled)
+		} else {
+			problems.add("$.item", "collection contains no enabled requests")
+		}
+	}
+	return problems.err()
+}
+
+// collectionSchemaVersion returns the supported format version the schema url of a collection
+// refers to, e.g. https://schema.getpostman.com/json/collection/v2.1.0/collection.json.
+func collectionSchemaVersion(schemaUrl string) string {
+	for version := range collectionSchemas {
+		if strings.Contains(schemaUrl, "/collection/"+version+"/") {
+			return version
+		}
+	}
+	return ""
+}
+
+// addSchemaProblems adds the innermost errors of the validation error. Of the alternatives of a
+// oneOf or anyOf, e.g. request or folder, only the one matching the value furthest is reported,
+// as the others usually describe a different kind of value.
+func addSchemaProblems(err *jsonschema.ValidationError, problems *validationProblems) {
+	if len(err.Causes) == 0 {
+		problems.add(jsonPathOf(err.InstanceLocation), "%s", err.ErrorKind.LocalizedString(schemaMessagePrinter))
+		return
+	}
+	switch err.ErrorKind.(type) {
+	case *kind.OneOf, *kind.AnyOf:
+		closest := err.Causes[0]
+		for _, cause := range err.Causes[1:] {
+			if schemaErrorDepth(cause) > schemaErrorDepth(closest) {
+				closest = cause
+			}
+		}
+		addSchemaProblems(closest, problems)
+		return
+	}
+	for _, cause := range err.Causes {
+		addSchemaProblems(cause, problems)
+	}
+}
+
+// schemaErrorDepth is the length of the deepest instance location of the errors.
+func schemaErrorDepth(err *jsonschema.ValidationError) int {
+	depth := len(err.InstanceLocation)
+	for _, cause := range err.Causes {
+		depth = max(depth, schemaErrorDepth(cause))
+	}
+	return depth
+}
+
+// jsonPathOf formats an instance location like $.item[0].request.
+func jsonPathOf(location []string) string {
+	path := "$"
+	for _, token := range location {
+		if _, err := strconv.Atoi(token); err == nil {
+			path += "[" + token + "]"
+		} else {
+			path += "." + token
+		}
+	}
+	return path
+}
+
+// validateItems checks the items and folders recursively and returns the number of enabled
+// requests and of disabled items among them.
+func validateItems(items []any, path string, problems *validationProblems) (requests, disabled int) {
+	for i, value := range items {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		item, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		if isDisabled, _ := item["disabled"].(bool); isDisabled {
+			disabled++
+			continue
+		}
+		if children, isFolder := item["item"].([]any); isFolder {
+			folderRequests, folderDisabled := validateItems(children, itemPath+".item", problems)
+			requests += folderRequests
+			disabled += folderDisabled
+			continue
+		}
+		if validateRequest(item["request"], itemPath+".request", problems) {
+			requests++
+		}
+	}
+	return requests, disabled
+}
+
+// validateRequest checks that the request has a url, which the schema does not require.
+func validateRequest(value any, path string, problems *validationProblems) bool {
+	switch request := value.(type) {
+	case string:
+		if strings.TrimSpace(request) == "" {
+			problems.add(path, "missing url")
+			return false
+		}
+		return true
+	case map[string]any:
+		switch url := request["url"].(type) {
+		case string:
+			if strings.TrimSpace(url) != "" {
+				return true
+			}
+		case map[string]any:
+			raw, _ := url["raw"].(string)
+			if host, hasHost := url["host"]; strings.TrimSpace(raw) != "" || (hasHost && host != nil) {
+				return true
+			}
+		}
+		problems.add(path+".url", "missing url")
+		return false
+	default:
+		return false
+	}
+}
+
+// validateEnvironmentFile checks the structure of a Postman environment file.
+func validateEnvironmentFile(path string) error {
+	environment, err := readJsonObject(path)
+	if err != nil {
+		return err
+	}
+
+	var problems validationProblems
+	values, ok := environment["values"].([]any)
+	if !ok {
+		problems.add("$.values", "missing values")
+		return problems.err()
+	}
+	for i, value := range values {
+		valuePath := fmt.Sprintf("$.values[%d]", i)
+		object, ok := value.(map[string]any)
+		if !ok {
+			problems.add(valuePath, "value must be an object")
+			continue
+		}
+		if key, _ := object["key"].(string); strings.TrimSpace(key) == "" {
+			problems.add(valuePath+".key", "missing key")
+		}
+		if enabled, ok := object["enabled"]; ok {
+			if _, isBool := enabled.(bool); !isBool {
+				problems.add(valuePath+".enabled", "enabled must be a boolean")
+			}
+		}
+	}
+	return problems.err()
+}
+
+func readJsonObject(path string) (map[string]any, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]any
+	if err := json.Unmarshal(content, &object); err != nil {
+		return nil, fmt.Errorf("not a valid JSON object: %w", err)
+	}
+	if object == nil {
+		return nil, errors.New("not a valid JSON object")
+	}
+	return object, nil
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "file.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestValidateCollectionFile(t *testing.T) {
+	const schema = `"schema":"https://schema.getpostman.com/json/collection/v2.1.0/collection.json"`
+	tests := []struct {
+		name       string
+		collection string
+		wantErr    string
+	}{
+		{
+			name:       "valid collection with folders",
+			collection: `{"info":{"name":"test",` + schema + `},"item":[{"name":"folder","item":[{"name":"get","request":{"method":"GET","url":{"raw":"https://example.com"},"header":[{"key":"Accept","value":"*/*"}]}}]},{"name":"short","request":"https://example.com"}]}`,
+		},
+		{
+			name:       "not json",
+			collection: `<html>`,
+			wantErr:    "not a valid JSON object",
+		},
+		{
+			name:       "unsupported schema",
+			collection: `{"info":{"name":"test","schema":"https://schema.getpostman.com/json/collection/v1.0.0/collection.json"},"item":[{"request":"https://example.com"}]}`,
+			wantErr:    `$.info.schema: unsupported collection format "https://schema.getpostman.com/json/collection/v1.0.0/collection.json"`,
+		},
+		{
+			name:       "valid v2.0.0 collection",
+			collection: `{"info":{"name":"test","schema":"https://schema.getpostman.com/json/collection/v2.0.0/collection.json"},"item":[{"name":"get","request":{"method":"GET","url":"https://example.com","auth":{"type":"basic","basic":{"username":"u","password":"p"}}}}]}`,
+		},
+		{
+			name:       "empty collection",
+			collection: `{"info":{"name":"test",` + schema + `},"item":[{"name":"folder","item":[]}]}`,
+			wantErr:    "$.item: collection contains no enabled requests",
+		},
+		{
+			name:       "only disabled requests",
+			collection: `{"info":{"name":"test",` + schema + `},"item":[{"name":"folder","item":[{"name":"get","disabled":true,"request":"https://example.com"}]},{"name":"post","disabled":true,"request":"https://example.com"}]}`,
+			wantErr:    "$.item: collection contains no enabled requests, 2 items are disabled",
+		},
+		{
+			name:       "schema violations",
+			collection: `{"info":{"name":"test",` + schema + `},"item":[{"name":"nothing"},{"request":"https://example.com","event":[{"script":{}}]}]}`,
+			wantErr:    `$.item[0]: missing property 'request'; $.item[1].event[0]: missing property 'listen'`,
+		},
+		{
+			name:       "missing url",
+			collection: `{"info":{"name":"test",` + schema + `},"item":[{"name":"get","request":{"method":"GET"}}]}`,
+			wantErr:    `$.item[0].request.url: missing url`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCollectionFile(writeTestFile(t, tt.collection))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateEnvironmentFile(t *testing.T) {
+	assert.NoError(t, validateEnvironmentFile(writeTestFile(t, `{"name":"env","values":[{"key":"host","value":"example.com","enabled":true}]}`)))
+
+	err := validateEnvironmentFile(writeTestFile(t, `{"name":"env"}`))
+	require.Error(t, err)
+	assert.Equal(t, "$.values: missing values", err.Error())
+
+	err = validateEnvironmentFile(writeTestFile(t, `{"values":[{"value":"x"},{"key":"a","enabled":"yes"}]}`))
+	require.Error(t, err)
+	assert.Equal(t, "$.values[0].key: missing key; $.values[1].enabled: enabled must be a boolean", err.Error())
+}
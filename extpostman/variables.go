@@ -0,0 +1,213 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	unresolvedVariablesFail   = "fail"
+	unresolvedVariablesWarn   = "warn"
+	unresolvedVariablesIgnore = "ignore"
+)
+
+var (
+	// e.g. {{baseUrl}}
+	variableReferencePattern = regexp.MustCompile(`\{\{([^{}]+)}}`)
+	// e.g. pm.environment.set("token", ...) or postman.setGlobalVariable('token', ...)
+	scriptVariablePattern = regexp.MustCompile(`(?:pm\.(?:environment|globals|variables|collectionVariables|iterationData)\.set|postman\.set(?:Environment|Global)Variable)\(\s*["'` + "`" + `]([^"'` + "`" + `]+)["'` + "`" + `]`)
+)
+
+// unresolvedVariable is a variable a request references but nothing defines.
+type unresolvedVariable struct {
+	name string
+	// request is the name of the first request referencing the variable
+	request string
+}
+
+func (v unresolvedVariable) String() string {
+	if v.request == "" {
+		return fmt.Sprintf("{{%s}}", v.name)
+	}
+	return fmt.Sprintf("{{%s}} (used in %q)", v.name, v.request)
+}
+
+// variableScan collects the variables defined by and referenced in a collection. Folder and
+// request variables are only defined within their item, so references are checked against the
+// run-wide definitions and the variables of the enclosing folders while walking the items.
+type variableScan struct {
+	defined    map[string]bool
+	unresolved []unresolvedVariable
+	seen       map[string]bool
+}
+
+// findUnresolvedVariables returns the variables referenced in the urls, headers, bodies and auth
+// blocks of the collection that are neither defined as collection, folder or environment
+// variables, passed as the given variable names, nor set by a script. Folder variables only
+// resolve the references within their folder. Disabled items are skipped, as newman doesn't run
+// them. Dynamic variables like {{$guid}} are always resolved by newman.
+func findUnresolvedVariables(collectionPath, environmentPath string, names []string) ([]unresolvedVariable, error) {
+	collection, err := readJsonObject(collectionPath)
+	if err != nil {
+		return nil, err
+	}
+	scan := &variableScan{defined: make(map[string]bool), seen: make(map[string]bool)}
+	for _, name := range names {
+		scan.defined[name] = true
+	}
+	if environmentPath != "" {
+		environment, err := readJsonObject(environmentPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if err == nil {
+			addVariables(scan.defined, environment["values"])
+		}
+	}
+
+	addVariables(scan.defined, collection["variable"])
+	scan.addScripts(collection["event"])
+	items, _ := collection["item"].([]any)
+	scan.addItemScripts(items)
+
+	scan.addReferences(collection["auth"], "", nil)
+	scan.addItems(items, nil)
+	return scan.unresolved, nil
+}
+
+// addItemScripts adds the variables set by the scripts of the items. Scripts set variables for
+// the rest of the run, not only for their item.
+func (s *variableScan) addItemScripts(items []any) {
+	for _, value := range items {
+		item, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		if disabled, _ := item["disabled"].(bool); disabled {
+			continue
+		}
+		s.addScripts(item["event"])
+		if children, ok := item["item"].([]any); ok {
+			s.addItemScripts(children)
+		}
+	}
+}
+
+// addItems checks the references of the items, with scope holding the variables of the
+// enclosing folders.
+func (s *variableScan) addItems(items []any, scope map[string]bool) {
+	for _, value := range items {
+		item, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		if disabled, _ := item["disabled"].(bool); disabled {
+			continue
+		}
+		name, _ := item["name"].(string)
+		itemScope := scope
+		if item["variable"] != nil {
+			itemScope = maps.Clone(scope)
+			if itemScope == nil {
+				itemScope = make(map[string]bool)
+			}
+			addVariables(itemScope, item["variable"])
+		}
+		s.addReferences(item["auth"], name, itemScope)
+		if children, ok := item["item"].([]any); ok {
+			s.addItems(children, itemScope)
+			continue
+		}
+		s.addReferences(item["request"], name, itemScope)
+	}
+}
+
+// addVariables adds the keys of the enabled variables to defined.
+func addVariables(defined map[string]bool, value any) {
+	variables, _ := value.([]any)
+	for _, variable := range variables {
+		object, ok := variable.(map[string]any)
+		if !ok {
+			continue
+		}
+		if enabled, ok := object["enabled"].(bool); ok && !enabled {
+			continue
+		}
+		if disabled, _ := object["disabled"].(bool); disabled {
+			continue
+		}
+		if key, _ := object["key"].(string); key != "" {
+			defined[key] = true
+		} else if id, _ := object["id"].(string); id != "" {
+			defined[id] = true
+		}
+	}
+}
+
+func (s *variableScan) addScripts(value any) {
+	events, _ := value.([]any)
+	for _, event := range events {
+		object, _ := event.(map[string]any)
+		script, _ := object["script"].(map[string]any)
+		var lines []string
+		switch exec := script["exec"].(type) {
+		case string:
+			lines = append(lines, exec)
+		case []any:
+			for _, line := range exec {
+				if text, ok := line.(string); ok {
+					lines = append(lines, text)
+				}
+			}
+		}
+		for _, match := range scriptVariablePattern.FindAllStringSubmatch(strings.Join(lines, "\n"), -1) {
+			s.defined[match[1]] = true
+		}
+	}
+}
+
+// addReferences collects the unresolved variable references in all strings of the value, except
+// for the descriptions, which newman does not resolve. Each variable is reported once, for the
+// first request it is unresolved in.
+func (s *variableScan) addReferences(value any, request string, scope map[string]bool) {
+	switch typed := value.(type) {
+	case string:
+		for _, match := range variableReferencePattern.FindAllStringSubmatch(typed, -1) {
+			name := strings.TrimSpace(match[1])
+			if name == "" || strings.HasPrefix(name, "$") || s.defined[name] || scope[name] || s.seen[name] {
+				continue
+			}
+			s.seen[name] = true
+			s.unresolved = append(s.unresolved, unresolvedVariable{name: name, request: request})
+		}
+	case map[string]any:
+		if disabled, _ := typed["disabled"].(bool); disabled {
+			return
+		}
+		for _, key := range slices.Sorted(maps.Keys(typed)) {
+			if key != "description" {
+				s.addReferences(typed[key], request, scope)
+			}
+		}
+	case []any:
+		for _, child := range typed {
+			s.addReferences(child, request, scope)
+		}
+	}
+}
+
+func describeUnresolvedVariables(variables []unresolvedVariable) string {
+	descriptions := make([]string, 0, len(variables))
+	for _, variable := range variables {
+		descriptions = append(descriptions, variable.String())
+	}
+	return strings.Join(descriptions, ", ")
+}
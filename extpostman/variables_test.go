@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindUnresolvedVariables(t *testing.T) {
+	collection := writeTestFile(t, `{
+		"info": {"name": "test"},
+		"variable": [{"key": "baseUrl", "value": "https://example.com"}, {"key": "off", "value": "x", "disabled": true}],
+		"auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}"}]},
+		"event": [{"listen": "prerequest", "script": {"exec": ["pm.environment.set('requestId', '1');"]}}],
+		"item": [
+			{
+				"name": "folder",
+				"variable": [{"key": "folderVar", "value": "1"}],
+				"item": [{
+					"name": "Get user",
+					"request": {
+						"method": "GET",
+						"url": {"raw": "{{baseUrl}}/users/{{userId}}?id={{$guid}}"},
+						"header": [{"key": "X-Request", "value": "{{requestId}} {{folderVar}}"}, {"key": "X-Off", "value": "{{off}}", "disabled": true}],
+						"description": "uses {{documented}}"
+					}
+				}]
+			},
+			{
+				"name": "Create user",
+				"request": {
+					"method": "POST",
+					"url": "{{ baseUrl }}/users",
+					"header": [{"key": "X-Env", "value": "{{envVar}} {{param}}"}],
+					"body": {"mode": "raw", "raw": "{\"tenant\": \"{{tenant}}\", \"user\": \"{{userId}}\", \"off\": \"{{off}}\"}"}
+				}
+			}
+		]
+	}`)
+	environment := filepath.Join(t.TempDir(), environmentFile)
+	require.NoError(t, os.WriteFile(environment, []byte(`{"values": [{"key": "envVar", "value": "1", "enabled": true}, {"key": "tenant", "value": "t", "enabled": false}]}`), 0600))
+
+	unresolved, err := findUnresolvedVariables(collection, environment, []string{"param"})
+
+	require.NoError(t, err)
+	assert.Equal(t, `{{token}}, {{userId}} (used in "Get user"), {{tenant}} (used in "Create user"), {{off}} (used in "Create user")`, describeUnresolvedVariables(unresolved))
+}
+
+func TestFindUnresolvedVariablesWithoutEnvironment(t *testing.T) {
+	collection := writeTestFile(t, `{"info": {"name": "test"}, "item": [{"name": "Get", "request": "{{baseUrl}}/health"}]}`)
+
+	unresolved, err := findUnresolvedVariables(collection, filepath.Join(t.TempDir(), environmentFile), nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []unresolvedVariable{{name: "baseUrl", request: "Get"}}, unresolved)
+}
+
+func TestFindUnresolvedVariablesScopesFolderVariablesToTheirFolder(t *testing.T) {
+	collection := writeTestFile(t, `{
+		"info": {"name": "test"},
+		"item": [
+			{
+				"name": "users",
+				"variable": [{"key": "userId", "value": "1"}],
+				"item": [
+					{"name": "Get user", "request": "https://example.com/users/{{userId}}"},
+					{"name": "admin", "variable": [{"key": "role", "value": "admin"}], "item": [{"name": "Get admin", "request": "https://example.com/users/{{userId}}/{{role}}"}]}
+				]
+			},
+			{"name": "Get role", "request": "https://example.com/roles/{{role}}"},
+			{"name": "Delete user", "request": "https://example.com/users/{{userId}}"},
+			{"name": "Disabled", "disabled": true, "request": "https://example.com/{{disabledVar}}"}
+		]
+	}`)
+
+	unresolved, err := findUnresolvedVariables(collection, "", nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []unresolvedVariable{{name: "role", request: "Get role"}, {name: "userId", request: "Delete user"}}, unresolved)
+}
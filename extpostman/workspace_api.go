@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	workspacesResource = "workspaces"
+
+	attributeWorkspaceId   = "postman.workspace.id"
+	attributeWorkspaceName = "postman.workspace.name"
+	attributeWorkspaceType = "postman.workspace.type"
+)
+
+type PostmanWorkspaceResult struct {
+	Workspaces []PostmanWorkspace `json:"workspaces"`
+}
+
+type PostmanWorkspace struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	// Type is personal, private, team, partner or public.
+	Type string `json:"type"`
+}
+
+func getPostmanWorkspaces(account postmanAccount) ([]PostmanWorkspace, error) {
+	var result PostmanWorkspaceResult
+	if err := callPostmanApi(postmanHttpClient, context.Background(), account, http.MethodGet, nil, &result, workspacesResource); err != nil {
+		return nil, err
+	}
+	return result.Workspaces, nil
+}
+
+// filterWorkspaces returns the workspaces whose id or name is in the allowed list, all if the
+// list is empty.
+func filterWorkspaces(workspaces []PostmanWorkspace, allowed []string) []PostmanWorkspace {
+	if len(allowed) == 0 {
+		return workspaces
+	}
+	var filtered []PostmanWorkspace
+	found := make(map[string]bool)
+	for _, workspace := range workspaces {
+		if slices.Contains(allowed, workspace.Id) || slices.Contains(allowed, workspace.Name) {
+			filtered = append(filtered, workspace)
+			found[workspace.Id] = true
+			found[workspace.Name] = true
+		}
+	}
+	for _, idOrName := range allowed {
+		if !found[idOrName] {
+			log.Warn().Msgf("Configured workspace '%s' does not exist or is not accessible with the API key", idOrName)
+		}
+	}
+	return filtered
+}
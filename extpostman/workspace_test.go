@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: 2026 Steadybit GmbH
+
+package extpostman
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/steadybit/extension-postman/v2/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPostmanWorkspaceApiStub(t *testing.T) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path + "?" + r.URL.RawQuery {
+		case "/workspaces?":
+			_, _ = w.Write([]byte(`{"workspaces":[{"id":"w1","name":"Checkout","type":"team"},{"id":"w2","name":"My Workspace","type":"personal"}]}`))
+		case "/collections?workspace=w1":
+			_, _ = w.Write([]byte(`{"collections":[{"id":"c1","name":"checkout"},{"id":"c2","name":"shared"}]}`))
+		case "/collections?workspace=w2":
+			_, _ = w.Write([]byte(`{"collections":[{"id":"c2","name":"shared"},{"id":"c3","name":"scratch"}]}`))
+		case "/environments?workspace=w1":
+			_, _ = w.Write([]byte(`{"environments":[{"id":"e1","name":"prod"}]}`))
+		case "/environments?workspace=w2":
+			_, _ = w.Write([]byte(`{"environments":[{"id":"e1","name":"prod"},{"id":"e3","name":"local"}]}`))
+		case "/environments?":
+			_, _ = w.Write([]byte(`{"environments":[{"id":"e1","name":"prod"},{"id":"e2","name":"prod"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_API_KEY", "123456")
+	t.Setenv("STEADYBIT_EXTENSION_POSTMAN_BASE_URL", server.URL)
+	config.ParseConfiguration()
+	t.Cleanup(func() { config.Config.PostmanWorkspaces = nil })
+}
+
+func TestApiSourceDiscoversCollectionsPerWorkspace(t *testing.T) {
+	newPostmanWorkspaceApiStub(t)
+
+	targets, err := apiSource{account: getPostmanAccounts()[0]}.discoverCollections()
+
+	require.NoError(t, err)
+	require.Len(t, targets, 3)
+	assert.Equal(t, "c1", targets[0].Id)
+	assert.Equal(t, []string{"w1"}, targets[0].Attributes["postman.workspace.id"])
+	assert.Equal(t, "c2", targets[1].Id)
+	assert.Equal(t, []string{"w1", "w2"}, targets[1].Attributes["postman.workspace.id"])
+	assert.Equal(t, []string{"Checkout", "My Workspace"}, targets[1].Attributes["postman.workspace.name"])
+	assert.Equal(t, []string{"team", "personal"}, targets[1].Attributes["postman.workspace.type"])
+	assert.Equal(t, "c3", targets[2].Id)
+}
+
+func TestApiSourceDiscoversEnvironmentsOfTheWorkspacesOfCollections(t *testing.T) {
+	newPostmanWorkspaceApiStub(t)
+
+	targets, err := apiSource{account: getPostmanAccounts()[0]}.discoverCollections()
+
+	require.NoError(t, err)
+	require.Len(t, targets, 3)
+	// collections only offer the environments of their workspaces
+	assert.Equal(t, []string{"e1"}, targets[0].Attributes["postman.environment.id"])
+	assert.Equal(t, []string{"prod"}, targets[0].Attributes["postman.environment.name"])
+	assert.Equal(t, []string{"e1", "e3"}, targets[1].Attributes["postman.environment.id"])
+	assert.Equal(t, []string{"prod", "local"}, targets[1].Attributes["postman.environment.name"])
+}
+
+func TestApiSourceDiscoversOnlyConfiguredWorkspaces(t *testing.T) {
+	newPostmanWorkspaceApiStub(t)
+	config.Config.PostmanWorkspaces = []string{"Checkout", "unknown"}
+
+	targets, err := apiSource{account: getPostmanAccounts()[0]}.discoverCollections()
+
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+	assert.Equal(t, "c1", targets[0].Id)
+	assert.Equal(t, "c2", targets[1].Id)
+	assert.Equal(t, []string{"w1"}, targets[1].Attributes["postman.workspace.id"])
+}
+
+func TestGetPostEnvironmentIdResolvesNamesWithinWorkspace(t *testing.T) {
+	newPostmanWorkspaceApiStub(t)
+
+	_, err := GetPostEnvironmentId(context.Background(), getPostmanAccounts()[0], "prod")
+	assert.ErrorContains(t, err, "found multiple environments with name 'prod'")
+
+	environmentId, err := GetPostEnvironmentId(context.Background(), getPostmanAccounts()[0], "prod", "w1")
+	require.NoError(t, err)
+	assert.Equal(t, "e1", environmentId)
+
+	// the environments of an inaccessible workspace cannot be listed
+	_, err = GetPostEnvironmentId(context.Background(), getPostmanAccounts()[0], "prod", "w9")
+	assert.ErrorContains(t, err, "failed to get environments")
+	assert.ErrorContains(t, err, "404")
+}
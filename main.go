@@ -31,6 +31,16 @@ func main() {
 	action_kit_sdk.RegisterCoverageEndpoints()
 	discovery_kit_sdk.Register(extpostman.NewPostmanCollectionDiscovery())
 	action_kit_sdk.RegisterAction(extpostman.NewPostmanAction())
+	discovery_kit_sdk.Register(extpostman.NewPostmanMonitorDiscovery())
+	action_kit_sdk.RegisterAction(extpostman.NewPostmanMonitorAction())
+	extpostman.InitRunHistory()
+	extpostman.RegisterRunHistoryHandlers()
+	extpostman.InitCache()
+	extpostman.InitApiKeyFile()
+	extpostman.InitHealthChecks()
+	extpostman.RegisterHealthHandlers()
+	extpostman.RegisterMetricsHandlers()
+	extpostman.RegisterCacheHandlers()
 	extsignals.ActivateSignalHandlers()
 
 	exthttp.RegisterRevisionedHandler("/", getExtensionList)